@@ -0,0 +1,69 @@
+package mapepire
+
+import "sync"
+
+// Represents the result of an asynchronous execution
+type AsyncResult struct {
+	Response *ServerResponse // The response of the server
+	Err      error           // The error, if any
+}
+
+// Execute a query/command asynchronously.
+// The result is sent on the returned channel once the execution is complete.
+// Several executions may run on the same job, their requests are serialized
+// on its connection.
+func (s *SQLJob) ExecuteAsync(command string, options QueryOptions) <-chan AsyncResult {
+	result := make(chan AsyncResult, 1)
+	go func() {
+		query, err := s.QueryWithOptions(command, options)
+		if err != nil {
+			result <- AsyncResult{Err: err}
+			return
+		}
+		resp, err := query.Execute()
+		result <- AsyncResult{Response: resp, Err: err}
+	}()
+	return result
+}
+
+// Execute a SQL query asynchronously, using a job from the pool.
+// The result is sent on the returned channel once the execution is complete.
+func (jp *JobPool) ExecuteAsync(command string, options QueryOptions) <-chan AsyncResult {
+	result := make(chan AsyncResult, 1)
+	go func() {
+		resp, err := jp.ExecuteSQLWithOptions(command, options)
+		result <- AsyncResult{Response: resp, Err: err}
+	}()
+	return result
+}
+
+// Execute all SQL queries concurrently across the jobs of the pool.
+// The results are in the same order as the queries.
+func (jp *JobPool) ExecuteAll(commands []string, options QueryOptions) []AsyncResult {
+	results := make([]AsyncResult, len(commands))
+	indexes := make(chan int)
+
+	workers := jp.options.MaxSize
+	if workers > len(commands) {
+		workers = len(commands)
+	}
+
+	wg := new(sync.WaitGroup)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				resp, err := jp.ExecuteSQLWithOptions(commands[index], options)
+				results[index] = AsyncResult{Response: resp, Err: err}
+			}
+		}()
+	}
+
+	for i := range commands {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,63 @@
+package mapepire
+
+import "testing"
+
+// Execute asynchronously on a job
+func TestExecuteAsync(t *testing.T) {
+	job := NewSQLJob("test")
+	job.Connect(server)
+
+	result := <-job.ExecuteAsync("VALUES 1", QueryOptions{})
+	if result.Err != nil {
+		t.Errorf("should not throw error")
+	}
+	if result.Response == nil || !result.Response.Success {
+		t.Errorf("should be successful")
+	}
+
+	result = <-job.ExecuteAsync("", QueryOptions{})
+	if result.Err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// Execute all queries with a pool
+func TestExecuteAll(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxWaitTime: 1, MaxSize: 2, StartingSize: 2})
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+
+	commands := []string{"VALUES 1", "VALUES 2", "VALUES 3"}
+	results := pool.ExecuteAll(commands, QueryOptions{TerseResult: true})
+	if len(results) != len(commands) {
+		t.Fatalf("have %v results, want %v", len(results), len(commands))
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Errorf("should not throw error")
+			continue
+		}
+		have := result.Response.TerseData[0][0]
+		if have != float64(i+1) {
+			t.Errorf("have %v, want %v", have, i+1)
+		}
+	}
+	pool.Close()
+}
+
+// Execute all queries with an unreachable pool
+func TestExecuteAllInvalid(t *testing.T) {
+	creds := DaemonServer{Host: "127.0.0.1", Port: "1", User: "user", Password: "pw"}
+	pool, _ := NewPool(PoolOptions{Creds: creds, MaxSize: 2, StartingSize: 2})
+
+	results := pool.ExecuteAll([]string{"VALUES 1", "VALUES 2", "VALUES 3"}, QueryOptions{})
+	if len(results) != 3 {
+		t.Fatalf("have %v results, want 3", len(results))
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("should throw error")
+		}
+	}
+}
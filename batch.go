@@ -0,0 +1,142 @@
+package mapepire
+
+import (
+	"errors"
+	"fmt"
+)
+
+const DEFAULT_BATCH_SIZE = 1000
+
+// Represents options for batch execution
+type BatchOptions struct {
+	BatchSize       int  // The amount of parameter rows sent per request
+	ContinueOnError bool // Whether to continue with the next rows after a row failed
+}
+
+// Represents the result of a batch execution
+type BatchResult struct {
+	UpdateCount int           // The total number of rows affected
+	Batches     []BatchStatus // The status of each batch
+	Errors      []BatchError  // The rows that failed
+}
+
+// Represents the status of a single batch
+type BatchStatus struct {
+	Start       int   // Index of the first row of the batch
+	End         int   // Index after the last row of the batch
+	UpdateCount int   // The number of rows affected by the batch
+	Err         error // The error of the batch, if any
+}
+
+// Represents a row that failed
+type BatchError struct {
+	Row        int   // Index of the row in the parameter rows, -1 if the row of a failed batch is unknown
+	Parameters []any // Parameters of the row, nil if the row is unknown
+	Err        error // The error of the row
+}
+
+func (e *BatchError) Error() string {
+	if e.Row < 0 {
+		return fmt.Sprintf("batch error in an unknown row: %v", e.Err)
+	}
+	return fmt.Sprintf("batch error in row %v: %v", e.Row, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Executes a prepared statement for all parameter rows, in batches of BatchSize rows.
+//
+// Under commitment control without autocommit, a failed batch is rolled back to
+// a savepoint and its rows are executed one by one to find the rows that failed.
+// Otherwise the rows the server applied before the error cannot be rolled back,
+// so no row of the failed batch is executed again: the batch is reported with an
+// error of an unknown row (Row -1) and the execution continues with the next batch.
+func (s *SQLJob) ExecuteBatch(sql string, rows [][]any, options BatchOptions) (*BatchResult, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("parameter rows required")
+	}
+	size := options.BatchSize
+	if size <= 0 {
+		size = DEFAULT_BATCH_SIZE
+	}
+
+	result := &BatchResult{}
+	savepoints := s.commitmentControl()
+
+	for start := 0; start < len(rows); {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		if savepoints {
+			savepoints = s.runCommand("SAVEPOINT MAPEPIRE_BATCH ON ROLLBACK RETAIN CURSORS", false) == nil
+		}
+
+		status := BatchStatus{Start: start, End: end}
+		status.UpdateCount, status.Err = s.executeRows(sql, rows[start:end])
+
+		var wsErr *WebsocketError
+		if errors.As(status.Err, &wsErr) {
+			result.Batches = append(result.Batches, status)
+			return result, status.Err
+		}
+
+		if status.Err != nil && !savepoints {
+			// rows applied before the error stay applied, the failing row is unknown
+			result.Errors = append(result.Errors, BatchError{Row: -1, Err: status.Err})
+		} else if status.Err != nil {
+			err := s.runCommand("ROLLBACK TO SAVEPOINT MAPEPIRE_BATCH", false)
+			if err != nil {
+				result.Batches = append(result.Batches, status)
+				return result, err
+			}
+
+			status.UpdateCount = 0
+			for i := start; i < end; i++ {
+				count, err := s.executeRows(sql, rows[i:i+1])
+				if err != nil {
+					result.Errors = append(result.Errors, BatchError{Row: i, Parameters: rows[i], Err: err})
+					if errors.As(err, &wsErr) {
+						result.Batches = append(result.Batches, status)
+						return result, err
+					}
+					if !options.ContinueOnError {
+						status.End = i + 1
+						break
+					}
+					continue
+				}
+				status.UpdateCount += count
+			}
+		}
+
+		result.UpdateCount += status.UpdateCount
+		result.Batches = append(result.Batches, status)
+
+		if len(result.Errors) > 0 && !options.ContinueOnError {
+			return result, &result.Errors[0]
+		}
+		start = end
+	}
+
+	if savepoints {
+		s.runCommand("RELEASE SAVEPOINT MAPEPIRE_BATCH", false)
+	}
+	return result, nil
+}
+
+// executes a prepared statement for the parameter rows
+func (s *SQLJob) executeRows(sql string, rows [][]any) (int, error) {
+	query, err := s.QueryWithOptions(sql, QueryOptions{Parameters: rows})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := query.Execute()
+	if err != nil {
+		return 0, err
+	}
+	return resp.UpdateCount, nil
+}
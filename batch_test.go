@@ -0,0 +1,94 @@
+package mapepire
+
+import "testing"
+
+func initBatchTable(t *testing.T) *SQLJob {
+	job := NewSQLJob("test")
+	err := job.Connect(server)
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	query, _ := job.Query("CREATE TABLE qtemp.BATCHTEST (ID decimal(8) NOT NULL PRIMARY KEY, DESCRIPTION VARCHAR(10) NOT NULL)")
+	_, err = query.Execute()
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	return job
+}
+
+// Execute a batch in multiple requests
+func TestExecuteBatch(t *testing.T) {
+	job := initBatchTable(t)
+
+	rows := [][]any{}
+	for i := 1; i <= 25; i++ {
+		rows = append(rows, []any{i, "row"})
+	}
+
+	result, err := job.ExecuteBatch("INSERT INTO BATCHTEST VALUES (?, ?)", rows, BatchOptions{BatchSize: 10})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if len(result.Batches) != 3 {
+		t.Errorf("have %v batches, want 3", len(result.Batches))
+	}
+	if result.UpdateCount != 25 {
+		t.Errorf("have %v, want 25", result.UpdateCount)
+	}
+}
+
+// Execute a batch with a failing row
+func TestExecuteBatchInvalid(t *testing.T) {
+	job := initBatchTable(t)
+
+	rows := [][]any{{1, "ok"}, {2, "much too long"}, {3, "ok"}}
+	result, err := job.ExecuteBatch("INSERT INTO BATCHTEST VALUES (?, ?)", rows, BatchOptions{BatchSize: 10, ContinueOnError: true})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	// with autocommit, the failing row of the batch is unknown
+	if len(result.Errors) != 1 || result.Errors[0].Row != -1 {
+		t.Errorf("have %v, want error in an unknown row", result.Errors)
+	}
+
+	_, err = job.ExecuteBatch("INSERT INTO BATCHTEST VALUES (?, ?)", [][]any{{4, "much too long"}}, BatchOptions{})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// Find the failing row of a batch rolled back to a savepoint
+func TestExecuteBatchSavepoint(t *testing.T) {
+	daemon := server
+	daemon.Properties = "auto commit=false;transaction isolation=read committed"
+	job := NewSQLJob("test")
+	err := job.Connect(daemon)
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	query, _ := job.Query("CREATE TABLE qtemp.BATCHTEST (ID decimal(8) NOT NULL PRIMARY KEY, DESCRIPTION VARCHAR(10) NOT NULL)")
+	_, err = query.Execute()
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+
+	rows := [][]any{{1, "ok"}, {2, "much too long"}, {3, "ok"}}
+	result, err := job.ExecuteBatch("INSERT INTO BATCHTEST VALUES (?, ?)", rows, BatchOptions{BatchSize: 10, ContinueOnError: true})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Row != 1 {
+		t.Errorf("have %v, want error in row 1", result.Errors)
+	}
+	if result.UpdateCount != 2 {
+		t.Errorf("have %v, want 2", result.UpdateCount)
+	}
+}
+
+func TestExecuteBatchEmpty(t *testing.T) {
+	job := NewSQLJob("test")
+	_, err := job.ExecuteBatch("INSERT INTO BATCHTEST VALUES (?, ?)", nil, BatchOptions{})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
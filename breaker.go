@@ -0,0 +1,111 @@
+package mapepire
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	CIRCUIT_CLOSED    = "CLOSED"
+	CIRCUIT_OPEN      = "OPEN"
+	CIRCUIT_HALF_OPEN = "HALF_OPEN"
+)
+const DEFAULT_BREAKER_COOLDOWN = 30
+
+// Represents a circuit breaker around connects and query executions of a pool
+type circuitBreaker struct {
+	threshold int           // Consecutive failures before the circuit opens (0 disables)
+	cooldown  time.Duration // Time the circuit stays open before probing
+	state     string        // The current state of the circuit
+	failures  int           // The number of consecutive failures
+	openedAt  time.Time     // When the circuit has been opened
+	probing   bool          // Whether a probe is running while half-open
+	lock      sync.Mutex    // Mutex
+}
+
+// Receive a new circuit breaker
+func newCircuitBreaker(threshold int, cooldown int) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = DEFAULT_BREAKER_COOLDOWN
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  time.Duration(cooldown) * time.Second,
+		state:     CIRCUIT_CLOSED,
+	}
+}
+
+// reports whether a request may pass and whether it is the probe of a half-open circuit
+func (cb *circuitBreaker) allow() (bool, error) {
+	if cb.threshold <= 0 {
+		return false, nil
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case CIRCUIT_OPEN:
+		if time.Since(cb.openedAt) < cb.cooldown {
+			return false, &CircuitOpenError{Method: "allow()", Message: fmt.Sprintf("%v consecutive failures", cb.failures)}
+		}
+		cb.state = CIRCUIT_HALF_OPEN
+		cb.probing = true
+		return true, nil
+	case CIRCUIT_HALF_OPEN:
+		if cb.probing {
+			return false, &CircuitOpenError{Method: "allow()", Message: "waiting for probe"}
+		}
+		cb.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// records the result of a connect or query execution
+func (cb *circuitBreaker) record(failed bool) {
+	if cb.threshold <= 0 {
+		return
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.probing = false
+	if !failed {
+		cb.failures = 0
+		cb.state = CIRCUIT_CLOSED
+		return
+	}
+
+	cb.failures++
+	if cb.state == CIRCUIT_HALF_OPEN || cb.failures >= cb.threshold {
+		cb.state = CIRCUIT_OPEN
+		cb.openedAt = time.Now()
+	}
+}
+
+// releases the probe without a result, another request may probe instead
+func (cb *circuitBreaker) releaseProbe() {
+	cb.lock.Lock()
+	cb.probing = false
+	cb.lock.Unlock()
+}
+
+// Receive the current state of the circuit breaker
+func (jp *JobPool) CircuitState() string {
+	jp.breaker.lock.Lock()
+	defer jp.breaker.lock.Unlock()
+	return jp.breaker.state
+}
+
+// verifies the connection of a job by running a trivial statement
+func (jp *JobPool) verifyJob(s *SQLJob) error {
+	query, err := s.Query("VALUES 1")
+	if err != nil {
+		return err
+	}
+	_, err = query.Execute()
+	return err
+}
@@ -0,0 +1,76 @@
+package mapepire
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	cb := newCircuitBreaker(2, 1)
+
+	cb.record(true)
+	if _, err := cb.allow(); err != nil {
+		t.Errorf("should not throw error")
+	}
+	cb.record(true)
+	if cb.state != CIRCUIT_OPEN {
+		t.Errorf("have %v, want %v", cb.state, CIRCUIT_OPEN)
+	}
+
+	_, err := cb.allow()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Errorf("have %v, want CircuitOpenError", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	probe, err := cb.allow()
+	if !probe || err != nil {
+		t.Errorf("should probe")
+	}
+	if _, err := cb.allow(); err == nil {
+		t.Errorf("should throw error while probing")
+	}
+
+	cb.record(false)
+	if cb.state != CIRCUIT_CLOSED {
+		t.Errorf("have %v, want %v", cb.state, CIRCUIT_CLOSED)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(0, 0)
+	for i := 0; i < 5; i++ {
+		cb.record(true)
+	}
+	if _, err := cb.allow(); err != nil {
+		t.Errorf("should not throw error")
+	}
+}
+
+// Get jobs from an unreachable host until the circuit opens
+func TestPoolCircuitBreaker(t *testing.T) {
+	creds := DaemonServer{Host: "127.0.0.1", Port: "1", User: "user", Password: "pw"}
+	pool, err := NewPool(PoolOptions{Creds: creds, MaxSize: 1, StartingSize: 1, BreakerThreshold: 2})
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = pool.GetJob()
+		var wsErr *WebsocketError
+		if !errors.As(err, &wsErr) {
+			t.Errorf("have %v, want WebsocketError", err)
+		}
+	}
+
+	_, err = pool.GetJob()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Errorf("have %v, want CircuitOpenError", err)
+	}
+	if pool.CircuitState() != CIRCUIT_OPEN {
+		t.Errorf("have %v, want %v", pool.CircuitState(), CIRCUIT_OPEN)
+	}
+}
@@ -0,0 +1,360 @@
+// Package catalog reads the QSYS2 catalog views of a job into typed structs:
+// schemas, tables, columns, indexes, keys and routines.
+//
+// Names are compared as they are stored in the catalog, which is uppercase
+// unless the object was created with a delimited name. Tables and schemas
+// are found by their SQL or system name.
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mapepire "github.com/deady54/mapepire-go"
+)
+
+const (
+	TABLE_TYPE_ALIAS    = "A"
+	TABLE_TYPE_LOGICAL  = "L"
+	TABLE_TYPE_MQT      = "M"
+	TABLE_TYPE_PHYSICAL = "P"
+	TABLE_TYPE_TABLE    = "T"
+	TABLE_TYPE_VIEW     = "V"
+)
+const (
+	CONSTRAINT_PRIMARY_KEY = "PRIMARY KEY"
+	CONSTRAINT_UNIQUE      = "UNIQUE"
+)
+const (
+	ROUTINE_PROCEDURE = "PROCEDURE"
+	ROUTINE_FUNCTION  = "FUNCTION"
+)
+
+// Reads the catalog with a job
+type Catalog struct {
+	job *mapepire.SQLJob // Connected job
+}
+
+// Receive a new catalog of the job
+func New(job *mapepire.SQLJob) *Catalog {
+	return &Catalog{job: job}
+}
+
+// Receive the schemas with names like the pattern (all if empty)
+func (c *Catalog) Schemas(pattern string) ([]Schema, error) {
+	rows, err := c.query(
+		"SELECT SCHEMA_NAME, SYSTEM_SCHEMA_NAME, SCHEMA_OWNER, SCHEMA_TEXT FROM QSYS2.SYSSCHEMAS WHERE SCHEMA_NAME LIKE ? OR SYSTEM_SCHEMA_NAME LIKE ? ORDER BY SCHEMA_NAME",
+		like(pattern), like(pattern),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]Schema, len(rows))
+	for i, r := range rows {
+		schemas[i] = Schema{
+			Name:       r.str("SCHEMA_NAME"),
+			SystemName: r.str("SYSTEM_SCHEMA_NAME"),
+			Owner:      r.str("SCHEMA_OWNER"),
+			Text:       r.str("SCHEMA_TEXT"),
+		}
+	}
+	return schemas, nil
+}
+
+// Receive the tables of the schema with names like the pattern (all if empty)
+func (c *Catalog) Tables(schema string, pattern string) ([]Table, error) {
+	rows, err := c.query(
+		"SELECT TABLE_SCHEMA, TABLE_NAME, SYSTEM_TABLE_SCHEMA, SYSTEM_TABLE_NAME, TABLE_TYPE, TABLE_TEXT FROM QSYS2.SYSTABLES WHERE (TABLE_SCHEMA = ? OR SYSTEM_TABLE_SCHEMA = ?) AND (TABLE_NAME LIKE ? OR SYSTEM_TABLE_NAME LIKE ?) ORDER BY TABLE_NAME",
+		schema, schema, like(pattern), like(pattern),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]Table, len(rows))
+	for i, r := range rows {
+		tables[i] = r.table()
+	}
+	return tables, nil
+}
+
+// Receive a table by its SQL or system name
+func (c *Catalog) Table(schema string, name string) (*Table, error) {
+	rows, err := c.query(
+		"SELECT TABLE_SCHEMA, TABLE_NAME, SYSTEM_TABLE_SCHEMA, SYSTEM_TABLE_NAME, TABLE_TYPE, TABLE_TEXT FROM QSYS2.SYSTABLES WHERE (TABLE_SCHEMA = ? OR SYSTEM_TABLE_SCHEMA = ?) AND (TABLE_NAME = ? OR SYSTEM_TABLE_NAME = ?)",
+		schema, schema, name, name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("table %v.%v not found", schema, name)
+	}
+	table := rows[0].table()
+	return &table, nil
+}
+
+// Receive the columns of a table in order
+func (c *Catalog) Columns(schema string, table string) ([]Column, error) {
+	t, err := c.Table(schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.query(
+		"SELECT COLUMN_NAME, SYSTEM_COLUMN_NAME, ORDINAL_POSITION, DATA_TYPE, LENGTH, NUMERIC_SCALE, IS_NULLABLE, HAS_DEFAULT, COLUMN_DEFAULT, IS_IDENTITY, COLUMN_TEXT, COLUMN_HEADING FROM QSYS2.SYSCOLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION",
+		t.Schema, t.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]Column, len(rows))
+	for i, r := range rows {
+		columns[i] = Column{
+			Name:       r.str("COLUMN_NAME"),
+			SystemName: r.str("SYSTEM_COLUMN_NAME"),
+			Position:   r.int("ORDINAL_POSITION"),
+			Type:       r.str("DATA_TYPE"),
+			Length:     r.int("LENGTH"),
+			Scale:      r.int("NUMERIC_SCALE"),
+			Nullable:   r.str("IS_NULLABLE") == "Y",
+			HasDefault: r.str("HAS_DEFAULT") != "N",
+			Default:    r.str("COLUMN_DEFAULT"),
+			Identity:   r.str("IS_IDENTITY") == "YES",
+			Text:       r.str("COLUMN_TEXT"),
+			Heading:    r.str("COLUMN_HEADING"),
+		}
+	}
+	return columns, nil
+}
+
+// Receive the indexes of a table with their key columns
+func (c *Catalog) Indexes(schema string, table string) ([]Index, error) {
+	t, err := c.Table(schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.query(
+		"SELECT I.INDEX_SCHEMA, I.INDEX_NAME, I.SYSTEM_INDEX_NAME, I.IS_UNIQUE, I.INDEX_TEXT, K.COLUMN_NAME, K.ORDERING FROM QSYS2.SYSINDEXES I JOIN QSYS2.SYSKEYS K ON K.INDEX_SCHEMA = I.INDEX_SCHEMA AND K.INDEX_NAME = I.INDEX_NAME WHERE I.TABLE_SCHEMA = ? AND I.TABLE_NAME = ? ORDER BY I.INDEX_SCHEMA, I.INDEX_NAME, K.ORDINAL_POSITION",
+		t.Schema, t.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []Index
+	for _, r := range rows {
+		schema, name := r.str("INDEX_SCHEMA"), r.str("INDEX_NAME")
+		if len(indexes) == 0 || indexes[len(indexes)-1].Schema != schema || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{
+				Schema:     schema,
+				Name:       name,
+				SystemName: r.str("SYSTEM_INDEX_NAME"),
+				Unique:     r.str("IS_UNIQUE") == "U" || r.str("IS_UNIQUE") == "V",
+				Text:       r.str("INDEX_TEXT"),
+			})
+		}
+		index := &indexes[len(indexes)-1]
+		index.Columns = append(index.Columns, r.str("COLUMN_NAME"))
+		index.Descending = append(index.Descending, r.str("ORDERING") == "D")
+	}
+	return indexes, nil
+}
+
+// Receive the primary key and unique constraints of a table
+func (c *Catalog) Keys(schema string, table string) ([]Key, error) {
+	t, err := c.Table(schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.query(
+		"SELECT C.CONSTRAINT_SCHEMA, C.CONSTRAINT_NAME, C.CONSTRAINT_TYPE, K.COLUMN_NAME FROM QSYS2.SYSCST C JOIN QSYS2.SYSKEYCST K ON K.CONSTRAINT_SCHEMA = C.CONSTRAINT_SCHEMA AND K.CONSTRAINT_NAME = C.CONSTRAINT_NAME WHERE C.TABLE_SCHEMA = ? AND C.TABLE_NAME = ? AND C.CONSTRAINT_TYPE IN ('PRIMARY KEY', 'UNIQUE') ORDER BY C.CONSTRAINT_TYPE, C.CONSTRAINT_SCHEMA, C.CONSTRAINT_NAME, K.ORDINAL_POSITION",
+		t.Schema, t.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, r := range rows {
+		schema, name := r.str("CONSTRAINT_SCHEMA"), r.str("CONSTRAINT_NAME")
+		if len(keys) == 0 || keys[len(keys)-1].Schema != schema || keys[len(keys)-1].Name != name {
+			keys = append(keys, Key{Schema: schema, Name: name, Type: r.str("CONSTRAINT_TYPE")})
+		}
+		key := &keys[len(keys)-1]
+		key.Columns = append(key.Columns, r.str("COLUMN_NAME"))
+	}
+	return keys, nil
+}
+
+// Receive the primary key of a table, nil if it has none
+func (c *Catalog) PrimaryKey(schema string, table string) (*Key, error) {
+	keys, err := c.Keys(schema, table)
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keys[i].Type == CONSTRAINT_PRIMARY_KEY {
+			return &keys[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Receive the foreign keys of a table with the referenced columns
+func (c *Catalog) ForeignKeys(schema string, table string) ([]ForeignKey, error) {
+	t, err := c.Table(schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.query(
+		"SELECT R.CONSTRAINT_SCHEMA, R.CONSTRAINT_NAME, R.UPDATE_RULE, R.DELETE_RULE, K.COLUMN_NAME, P.TABLE_SCHEMA AS REFERENCED_SCHEMA, P.TABLE_NAME AS REFERENCED_TABLE, PK.COLUMN_NAME AS REFERENCED_COLUMN "+
+			"FROM QSYS2.SYSREFCST R "+
+			"JOIN QSYS2.SYSCST C ON C.CONSTRAINT_SCHEMA = R.CONSTRAINT_SCHEMA AND C.CONSTRAINT_NAME = R.CONSTRAINT_NAME "+
+			"JOIN QSYS2.SYSKEYCST K ON K.CONSTRAINT_SCHEMA = R.CONSTRAINT_SCHEMA AND K.CONSTRAINT_NAME = R.CONSTRAINT_NAME "+
+			"JOIN QSYS2.SYSCST P ON P.CONSTRAINT_SCHEMA = R.UNIQUE_CONSTRAINT_SCHEMA AND P.CONSTRAINT_NAME = R.UNIQUE_CONSTRAINT_NAME "+
+			"JOIN QSYS2.SYSKEYCST PK ON PK.CONSTRAINT_SCHEMA = P.CONSTRAINT_SCHEMA AND PK.CONSTRAINT_NAME = P.CONSTRAINT_NAME AND PK.ORDINAL_POSITION = K.ORDINAL_POSITION "+
+			"WHERE C.TABLE_SCHEMA = ? AND C.TABLE_NAME = ? ORDER BY R.CONSTRAINT_SCHEMA, R.CONSTRAINT_NAME, K.ORDINAL_POSITION",
+		t.Schema, t.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var foreignKeys []ForeignKey
+	for _, r := range rows {
+		schema, name := r.str("CONSTRAINT_SCHEMA"), r.str("CONSTRAINT_NAME")
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Schema != schema || foreignKeys[len(foreignKeys)-1].Name != name {
+			foreignKeys = append(foreignKeys, ForeignKey{
+				Schema:           schema,
+				Name:             name,
+				ReferencedSchema: r.str("REFERENCED_SCHEMA"),
+				ReferencedTable:  r.str("REFERENCED_TABLE"),
+				UpdateRule:       r.str("UPDATE_RULE"),
+				DeleteRule:       r.str("DELETE_RULE"),
+			})
+		}
+		key := &foreignKeys[len(foreignKeys)-1]
+		key.Columns = append(key.Columns, r.str("COLUMN_NAME"))
+		key.ReferencedColumns = append(key.ReferencedColumns, r.str("REFERENCED_COLUMN"))
+	}
+	return foreignKeys, nil
+}
+
+// Receive the procedures and functions of the schema with names like the pattern (all if empty)
+func (c *Catalog) Routines(schema string, pattern string) ([]Routine, error) {
+	rows, err := c.query(
+		"SELECT ROUTINE_SCHEMA, ROUTINE_NAME, SPECIFIC_SCHEMA, SPECIFIC_NAME, ROUTINE_TYPE, MAX_DYNAMIC_RESULT_SETS, ROUTINE_TEXT FROM QSYS2.SYSROUTINES WHERE ROUTINE_SCHEMA = ? AND ROUTINE_NAME LIKE ? ORDER BY ROUTINE_NAME, SPECIFIC_NAME",
+		schema, like(pattern),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	routines := make([]Routine, len(rows))
+	index := make(map[string]int, len(rows))
+	for i, r := range rows {
+		routines[i] = Routine{
+			Schema:       r.str("ROUTINE_SCHEMA"),
+			Name:         r.str("ROUTINE_NAME"),
+			SpecificName: r.str("SPECIFIC_NAME"),
+			Type:         r.str("ROUTINE_TYPE"),
+			ResultSets:   r.int("MAX_DYNAMIC_RESULT_SETS"),
+			Text:         r.str("ROUTINE_TEXT"),
+		}
+		index[r.str("SPECIFIC_SCHEMA")+"."+routines[i].SpecificName] = i
+	}
+	if len(routines) == 0 {
+		return routines, nil
+	}
+
+	rows, err = c.query(
+		"SELECT P.SPECIFIC_SCHEMA, P.SPECIFIC_NAME, P.PARAMETER_NAME, P.ORDINAL_POSITION, P.PARAMETER_MODE, P.DATA_TYPE, COALESCE(P.CHARACTER_MAXIMUM_LENGTH, P.NUMERIC_PRECISION) AS LENGTH, P.NUMERIC_SCALE, P.IS_NULLABLE FROM QSYS2.SYSPARMS P JOIN QSYS2.SYSROUTINES R ON R.SPECIFIC_SCHEMA = P.SPECIFIC_SCHEMA AND R.SPECIFIC_NAME = P.SPECIFIC_NAME WHERE R.ROUTINE_SCHEMA = ? AND R.ROUTINE_NAME LIKE ? AND P.ROW_TYPE IN ('P', 'B') ORDER BY P.SPECIFIC_SCHEMA, P.SPECIFIC_NAME, P.ORDINAL_POSITION",
+		schema, like(pattern),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		i, ok := index[r.str("SPECIFIC_SCHEMA")+"."+r.str("SPECIFIC_NAME")]
+		if !ok {
+			continue
+		}
+		routines[i].Parameters = append(routines[i].Parameters, Parameter{
+			Name:     r.str("PARAMETER_NAME"),
+			Position: r.int("ORDINAL_POSITION"),
+			Mode:     r.str("PARAMETER_MODE"),
+			Type:     r.str("DATA_TYPE"),
+			Length:   r.int("LENGTH"),
+			Scale:    r.int("NUMERIC_SCALE"),
+			Nullable: r.str("IS_NULLABLE") == "YES",
+		})
+	}
+	return routines, nil
+}
+
+// Represents a row of a catalog view
+type row map[string]any
+
+// runs a catalog query and receives all rows
+func (c *Catalog) query(sql string, parameters ...any) ([]row, error) {
+	query, err := c.job.QueryWithOptions(sql, mapepire.QueryOptions{Rows: mapepire.MAX_FETCH_SIZE, Parameters: [][]any{parameters}})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []row
+	err = query.Stream(func(page *mapepire.ServerResponse) error {
+		for _, data := range page.Data {
+			rows = append(rows, data)
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// receives a table of the row
+func (r row) table() Table {
+	return Table{
+		Schema:       r.str("TABLE_SCHEMA"),
+		Name:         r.str("TABLE_NAME"),
+		SystemSchema: r.str("SYSTEM_TABLE_SCHEMA"),
+		SystemName:   r.str("SYSTEM_TABLE_NAME"),
+		Type:         r.str("TABLE_TYPE"),
+		Text:         r.str("TABLE_TEXT"),
+	}
+}
+
+// receives a value of the row as text without padding, empty if NULL
+func (r row) str(name string) string {
+	switch v := r[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// receives a value of the row as integer, 0 if NULL
+func (r row) int(name string) int {
+	value, _ := strconv.Atoi(r.str(name))
+	return value
+}
+
+// converts an empty pattern to match all names
+func like(pattern string) string {
+	if pattern == "" {
+		return "%"
+	}
+	return pattern
+}
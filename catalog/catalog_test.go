@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"os"
+	"testing"
+
+	mapepire "github.com/deady54/mapepire-go"
+	"github.com/joho/godotenv"
+)
+
+func getServer() mapepire.DaemonServer {
+	godotenv.Load("../.env")
+	return mapepire.DaemonServer{
+		Host:               os.Getenv("VITE_SERVER"),
+		User:               os.Getenv("VITE_DB_USER"),
+		Password:           os.Getenv("VITE_DB_PASS"),
+		Port:               os.Getenv("VITE_PORT"),
+		IgnoreUnauthorized: true,
+	}
+}
+
+var server = getServer()
+
+func TestRow(t *testing.T) {
+	r := row{"NAME": "EMPNO     ", "LENGTH": 6.0, "SCALE": nil}
+	if have := r.str("NAME"); have != "EMPNO" {
+		t.Errorf("have %q, want EMPNO", have)
+	}
+	if have := r.int("LENGTH"); have != 6 {
+		t.Errorf("have %v, want 6", have)
+	}
+	if have := r.int("SCALE"); have != 0 {
+		t.Errorf("have %v, want 0", have)
+	}
+	if have := r.str("MISSING"); have != "" {
+		t.Errorf("have %q, want empty", have)
+	}
+}
+
+func TestLike(t *testing.T) {
+	if like("") != "%" || like("EMP%") != "EMP%" {
+		t.Errorf("should match all names if empty")
+	}
+}
+
+// Columns of a table
+func TestColumns(t *testing.T) {
+	job := mapepire.NewSQLJob("test")
+	job.Connect(server)
+
+	columns, err := New(job).Columns("SAMPLE", "EMPLOYEE")
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	if len(columns) == 0 || columns[0].Name != "EMPNO" || columns[0].Position != 1 {
+		t.Errorf("have %+v, want EMPNO first", columns)
+	}
+}
+
+// Primary key of a table
+func TestPrimaryKey(t *testing.T) {
+	job := mapepire.NewSQLJob("test")
+	job.Connect(server)
+
+	key, err := New(job).PrimaryKey("SAMPLE", "DEPARTMENT")
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	if key == nil || len(key.Columns) != 1 || key.Columns[0] != "DEPTNO" {
+		t.Errorf("have %+v, want DEPTNO", key)
+	}
+}
+
+// Table not found
+func TestTableNotFound(t *testing.T) {
+	job := mapepire.NewSQLJob("test")
+	job.Connect(server)
+
+	_, err := New(job).Table("SAMPLE", "NOTEXIST")
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
@@ -0,0 +1,88 @@
+package catalog
+
+// Represents a schema (library)
+type Schema struct {
+	Name       string // SQL name of the schema
+	SystemName string // System name of the schema
+	Owner      string // Owner of the schema
+	Text       string // Description of the schema
+}
+
+// Represents a table, view or physical/logical file
+type Table struct {
+	Schema       string // SQL name of the schema
+	Name         string // SQL name of the table
+	SystemSchema string // System name of the schema
+	SystemName   string // System name of the table
+	Type         string // One of TABLE_TYPE_*
+	Text         string // Description of the table
+}
+
+// Represents a column of a table
+type Column struct {
+	Name       string // SQL name of the column
+	SystemName string // System name of the column
+	Position   int    // Ordinal position in the table, starting at 1
+	Type       string // Data type, e.g. DECIMAL or VARCHAR
+	Length     int    // Length or precision of the column
+	Scale      int    // Scale of numeric columns
+	Nullable   bool   // Whether the column can be NULL
+	HasDefault bool   // Whether the column has a default value
+	Default    string // Default value, if any
+	Identity   bool   // Whether the column is an identity column
+	Text       string // Description of the column
+	Heading    string // Column heading
+}
+
+// Represents an index of a table
+type Index struct {
+	Schema     string   // SQL name of the schema
+	Name       string   // SQL name of the index
+	SystemName string   // System name of the index
+	Unique     bool     // Whether the keys of the index are unique
+	Columns    []string // Key columns in order
+	Descending []bool   // Whether the key columns are in descending order
+	Text       string   // Description of the index
+}
+
+// Represents a primary key or unique constraint
+type Key struct {
+	Schema  string   // Schema of the constraint
+	Name    string   // Name of the constraint
+	Type    string   // CONSTRAINT_PRIMARY_KEY or CONSTRAINT_UNIQUE
+	Columns []string // Key columns in order
+}
+
+// Represents a foreign key constraint
+type ForeignKey struct {
+	Schema            string   // Schema of the constraint
+	Name              string   // Name of the constraint
+	Columns           []string // Key columns in order
+	ReferencedSchema  string   // Schema of the referenced table
+	ReferencedTable   string   // Referenced table
+	ReferencedColumns []string // Referenced columns in order
+	UpdateRule        string   // Rule on updates of the parent, e.g. RESTRICT
+	DeleteRule        string   // Rule on deletes of the parent, e.g. CASCADE
+}
+
+// Represents a procedure or function
+type Routine struct {
+	Schema       string      // Schema of the routine
+	Name         string      // Name of the routine
+	SpecificName string      // Specific name of the routine
+	Type         string      // ROUTINE_PROCEDURE or ROUTINE_FUNCTION
+	ResultSets   int         // Max result sets of a procedure
+	Parameters   []Parameter // Parameters in order
+	Text         string      // Description of the routine
+}
+
+// Represents a parameter of a routine
+type Parameter struct {
+	Name     string // Name of the parameter
+	Position int    // Ordinal position, starting at 1
+	Mode     string // IN, OUT or INOUT
+	Type     string // Data type
+	Length   int    // Length or precision of the parameter
+	Scale    int    // Scale of numeric parameters
+	Nullable bool   // Whether the parameter can be NULL
+}
@@ -0,0 +1,213 @@
+// Package cl builds CL commands with the quoting rules of the IBM i command
+// language, so values are passed to the command as intended.
+//
+//	cmd := cl.Command("CRTLIB").Param("LIB", "MYLIB").Param("TEXT", "My 'cool' lib")
+//	result, err := cmd.Execute(job)
+package cl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	mapepire "github.com/deady54/mapepire-go"
+)
+
+var (
+	namePattern    = regexp.MustCompile(`^[A-Z$#@][A-Z0-9$#@_.]*$`)
+	specialPattern = regexp.MustCompile(`^\*[A-Z0-9$#@_.]+$`)
+	genericPattern = regexp.MustCompile(`^[A-Z$#@][A-Z0-9$#@_.]*\*$`)
+	numberPattern  = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+	commandPattern = regexp.MustCompile(`^([A-Z$#@][A-Z0-9$#@_.]*/)?[A-Z$#@][A-Z0-9$#@_.]*$`)
+)
+
+// Represents a value of a parameter
+type Value interface {
+	clValue() (string, error)
+}
+
+// Represents a CL command
+type Cmd struct {
+	name   string   // Name of the command, optionally qualified
+	params []string // Parameters in keyword notation
+	err    error    // The first error while building the command
+}
+
+// Receive a new command with the name, e.g. CRTLIB or QSYS/CRTLIB
+func Command(name string) *Cmd {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	c := &Cmd{name: name}
+	if !commandPattern.MatchString(name) {
+		c.err = fmt.Errorf("invalid command name: %q", name)
+	}
+	return c
+}
+
+// Adds a parameter to the command. Several values form a list.
+// Strings are passed as they are if they are names, special values (*LIBL),
+// generic names (ABC*) or numbers, otherwise they are quoted.
+// Lowercase names are quoted as well, use Name to pass them as names.
+func (c *Cmd) Param(keyword string, values ...any) *Cmd {
+	if c.err != nil {
+		return c
+	}
+
+	keyword = strings.ToUpper(strings.TrimSpace(keyword))
+	if !namePattern.MatchString(keyword) {
+		c.err = fmt.Errorf("invalid keyword: %q", keyword)
+		return c
+	}
+	if len(values) == 0 {
+		c.err = fmt.Errorf("no value for keyword %v", keyword)
+		return c
+	}
+
+	list, err := formatList(values)
+	if err != nil {
+		c.err = fmt.Errorf("invalid value for keyword %v: %v", keyword, err)
+		return c
+	}
+	c.params = append(c.params, keyword+"("+list+")")
+	return c
+}
+
+// Builds the command string
+func (c *Cmd) Build() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	if len(c.params) == 0 {
+		return c.name, nil
+	}
+	return c.name + " " + strings.Join(c.params, " "), nil
+}
+
+// Receive the command string, empty if the command is invalid
+func (c *Cmd) String() string {
+	command, _ := c.Build()
+	return command
+}
+
+// Runs the command on the job
+func (c *Cmd) Execute(job *mapepire.SQLJob) (*mapepire.CLResult, error) {
+	command, err := c.Build()
+	if err != nil {
+		return nil, err
+	}
+	return job.ExecuteCL(command)
+}
+
+// A string value, which is always quoted
+type text string
+
+// Receive a value that is always quoted, e.g. for descriptions
+func String(value string) Value {
+	return text(value)
+}
+
+func (t text) clValue() (string, error) {
+	return quote(string(t)), nil
+}
+
+// A name value, which is never quoted
+type name string
+
+// Receive a name (or special value), which is converted to uppercase
+func Name(value string) Value {
+	return name(value)
+}
+
+func (n name) clValue() (string, error) {
+	value := strings.ToUpper(strings.TrimSpace(string(n)))
+	if !namePattern.MatchString(value) && !specialPattern.MatchString(value) && !genericPattern.MatchString(value) {
+		return "", fmt.Errorf("invalid name: %q", string(n))
+	}
+	return value, nil
+}
+
+// A qualified name, e.g. LIB/OBJ
+type qualified []string
+
+// Receive a qualified name of the parts, e.g. Qualified("MYLIB", "MYFILE") for MYLIB/MYFILE
+func Qualified(parts ...string) Value {
+	return qualified(parts)
+}
+
+func (q qualified) clValue() (string, error) {
+	if len(q) == 0 {
+		return "", fmt.Errorf("empty qualified name")
+	}
+	parts := make([]string, len(q))
+	for i, part := range q {
+		value, err := name(part).clValue()
+		if err != nil {
+			return "", err
+		}
+		parts[i] = value
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// A group of elements
+type elements []any
+
+// Receive a group of elements, e.g. Elements("*FILE", 10) for (*FILE 10)
+func Elements(values ...any) Value {
+	return elements(values)
+}
+
+func (e elements) clValue() (string, error) {
+	list, err := formatList(e)
+	if err != nil {
+		return "", err
+	}
+	return "(" + list + ")", nil
+}
+
+// formats the values separated by blanks
+func formatList(values []any) (string, error) {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		v, err := format(value)
+		if err != nil {
+			return "", err
+		}
+		formatted[i] = v
+	}
+	return strings.Join(formatted, " "), nil
+}
+
+// formats a single value
+func format(value any) (string, error) {
+	switch v := value.(type) {
+	case Value:
+		return v.clValue()
+	case string:
+		if namePattern.MatchString(v) || specialPattern.MatchString(v) || genericPattern.MatchString(v) || numberPattern.MatchString(v) {
+			return v, nil
+		}
+		return quote(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "*YES", nil
+		}
+		return "*NO", nil
+	}
+	return "", fmt.Errorf("unsupported type %T", value)
+}
+
+// quotes the text, apostrophes are doubled
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
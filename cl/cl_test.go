@@ -0,0 +1,46 @@
+package cl
+
+import "testing"
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		cmd  *Cmd
+		want string
+	}{
+		{Command("crtlib").Param("LIB", "MYLIB").Param("TEXT", "My 'cool' lib"), "CRTLIB LIB(MYLIB) TEXT('My ''cool'' lib')"},
+		{Command("DLTF").Param("FILE", Qualified("mylib", "myfile")), "DLTF FILE(MYLIB/MYFILE)"},
+		{Command("CHGLIBL").Param("LIBL", "QGPL", "QTEMP", "*NONE"), "CHGLIBL LIBL(QGPL QTEMP *NONE)"},
+		{Command("DSPOBJD").Param("OBJ", Qualified("*LIBL", "ABC*")).Param("OBJTYPE", "*ALL"), "DSPOBJD OBJ(*LIBL/ABC*) OBJTYPE(*ALL)"},
+		{Command("CRTPF").Param("FILE", "MYLIB/TEST").Param("RCDLEN", 80), "CRTPF FILE('MYLIB/TEST') RCDLEN(80)"},
+		{Command("CHGJOB").Param("LOG", 4, 0, "*SECLVL"), "CHGJOB LOG(4 0 *SECLVL)"},
+		{Command("CRTCMD").Param("PRDLIB", "*NOCHG").Param("HLPPNLGRP", Elements(Qualified("QGPL", "HELP"), "*NONE"), Elements("*LIBL", 1)), "CRTCMD PRDLIB(*NOCHG) HLPPNLGRP((QGPL/HELP *NONE) (*LIBL 1))"},
+		{Command("SNDMSG").Param("MSG", String("STOP")).Param("TOUSR", Name("qsysopr")), "SNDMSG MSG('STOP') TOUSR(QSYSOPR)"},
+		{Command("CHGOBJD").Param("TEXT", ""), "CHGOBJD TEXT('')"},
+		{Command("QSYS/SIGNOFF"), "QSYS/SIGNOFF"},
+	}
+	for _, test := range tests {
+		have, err := test.cmd.Build()
+		if err != nil {
+			t.Errorf("should not throw error: %v", err)
+		}
+		if have != test.want {
+			t.Errorf("have %v, want %v", have, test.want)
+		}
+	}
+}
+
+func TestCommandInvalid(t *testing.T) {
+	tests := []*Cmd{
+		Command("CRTLIB LIB(X)"),
+		Command("CRTLIB").Param("LIB) TEXT(", "X"),
+		Command("CRTLIB").Param("LIB"),
+		Command("CRTLIB").Param("LIB", Name("MY LIB")),
+		Command("DLTF").Param("FILE", Qualified("MYLIB", "A'B")),
+		Command("CRTLIB").Param("LIB", []string{"X"}),
+	}
+	for _, cmd := range tests {
+		if _, err := cmd.Build(); err == nil {
+			t.Errorf("should throw error for %v", cmd.String())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package mapepire
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MESSAGE_TYPE_COMPLETION    = "COMPLETION"
+	MESSAGE_TYPE_DIAGNOSTIC    = "DIAGNOSTIC"
+	MESSAGE_TYPE_ESCAPE        = "ESCAPE"
+	MESSAGE_TYPE_INFORMATIONAL = "INFORMATIONAL"
+	MESSAGE_TYPE_NOTIFY        = "NOTIFY"
+	MESSAGE_TYPE_REQUEST       = "REQUEST"
+)
+
+// Represents a job log message of a CL command
+type CLMessage struct {
+	ID              string    // Message ID, e.g. CPF2111
+	Type            string    // Message type, e.g. ESCAPE or COMPLETION
+	Severity        int       // Severity (0 to 99)
+	Text            string    // First-level message text
+	SecondLevelText string    // Second-level message text
+	Timestamp       time.Time // Time the message was sent
+}
+
+// Represents the result of a CL command
+type CLResult struct {
+	Command  string          // The CL command
+	Success  bool            // Whether the command ran without escape messages
+	Messages []CLMessage     // Job log messages of the command
+	Response *ServerResponse // Response of the server
+}
+
+// Runs the CL command and returns its result with the parsed job log messages.
+// The result is returned even if the command failed, together with the error.
+func (s *SQLJob) ExecuteCL(command string) (*CLResult, error) {
+	query, err := s.ClCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := query.Execute()
+	var wsErr *WebsocketError
+	if errors.As(err, &wsErr) {
+		return nil, err
+	}
+	result := NewCLResult(command, resp)
+	if err != nil {
+		result.Success = false
+	}
+	return result, err
+}
+
+// Receive the result of a CL command from the response of the server
+func NewCLResult(command string, resp *ServerResponse) *CLResult {
+	result := &CLResult{Command: command, Response: resp, Success: resp.Error == nil}
+	for _, data := range resp.Data {
+		message := CLMessage{
+			ID:              clField(data, "MESSAGE_ID"),
+			Type:            strings.ToUpper(clField(data, "MESSAGE_TYPE")),
+			Text:            clField(data, "MESSAGE_TEXT"),
+			SecondLevelText: clField(data, "MESSAGE_SECOND_LEVEL_TEXT"),
+		}
+		message.Severity, _ = strconv.Atoi(clField(data, "SEVERITY"))
+		message.Timestamp, _ = parseTemporal("TIMESTAMP", clField(data, "MESSAGE_TIMESTAMP"))
+
+		if message.Type == MESSAGE_TYPE_ESCAPE {
+			result.Success = false
+		}
+		result.Messages = append(result.Messages, message)
+	}
+	return result
+}
+
+// Reports whether a message with the ID has been sent
+func (r *CLResult) HasMessage(ID string) bool {
+	return r.Message(ID) != nil
+}
+
+// Receive the first message with the ID, nil if there is none
+func (r *CLResult) Message(ID string) *CLMessage {
+	for i := range r.Messages {
+		if strings.EqualFold(r.Messages[i].ID, ID) {
+			return &r.Messages[i]
+		}
+	}
+	return nil
+}
+
+// Receive the escape messages of the command
+func (r *CLResult) Escapes() []CLMessage {
+	var escapes []CLMessage
+	for _, message := range r.Messages {
+		if message.Type == MESSAGE_TYPE_ESCAPE {
+			escapes = append(escapes, message)
+		}
+	}
+	return escapes
+}
+
+// receives a field of a message as text
+func clField(data map[string]any, name string) string {
+	value, ok := data[name]
+	if !ok || value == nil {
+		return ""
+	}
+	return strings.TrimSpace(formatValue(value))
+}
@@ -0,0 +1,60 @@
+package mapepire
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewCLResult(t *testing.T) {
+	resp := &ServerResponse{
+		Success: true,
+		Data: []map[string]any{
+			{"MESSAGE_ID": "CPC2102", "MESSAGE_TYPE": "COMPLETION", "SEVERITY": 0.0, "MESSAGE_TEXT": "Library MYLIB created.", "MESSAGE_TIMESTAMP": "2024-03-01 10:15:30.123456"},
+		},
+	}
+	result := NewCLResult("CRTLIB LIB(MYLIB)", resp)
+	if !result.Success {
+		t.Errorf("should be successful")
+	}
+	if !result.HasMessage("cpc2102") {
+		t.Errorf("should have message CPC2102")
+	}
+	message := result.Message("CPC2102")
+	if message.Text != "Library MYLIB created." || message.Timestamp.Year() != 2024 {
+		t.Errorf("have %+v", message)
+	}
+}
+
+func TestNewCLResultEscape(t *testing.T) {
+	resp := &ServerResponse{
+		Error: fmt.Errorf("CPF2111"),
+		Data: []map[string]any{
+			{"MESSAGE_ID": "CPD0030", "MESSAGE_TYPE": "DIAGNOSTIC", "SEVERITY": 30.0},
+			{"MESSAGE_ID": "CPF2111", "MESSAGE_TYPE": "ESCAPE", "SEVERITY": 40.0, "MESSAGE_TEXT": "Library MYLIB already exists."},
+		},
+	}
+	result := NewCLResult("CRTLIB LIB(MYLIB)", resp)
+	if result.Success {
+		t.Errorf("should not be successful")
+	}
+	if len(result.Escapes()) != 1 || result.Escapes()[0].Severity != 40 {
+		t.Errorf("have %+v, want one escape message with severity 40", result.Escapes())
+	}
+	if result.HasMessage("CPF9801") {
+		t.Errorf("should not have message CPF9801")
+	}
+}
+
+// Run a failing CL command
+func TestExecuteCL(t *testing.T) {
+	job := NewSQLJob("test")
+	job.Connect(server)
+
+	result, err := job.ExecuteCL("DLTLIB LIB(NOTEXIST)")
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+	if result == nil || result.Success || !result.HasMessage("CPF2110") {
+		t.Errorf("should have escape message CPF2110")
+	}
+}
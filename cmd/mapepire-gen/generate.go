@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/deady54/mapepire-go/catalog"
+)
+
+// Name of the file with the helpers of the generated code, written next to the output file
+const helpersFile = "mapepire_helpers.go"
+
+// Matches identifiers that can be used without delimiters
+var ordinaryIdentifier = regexp.MustCompile(`^[A-Z#@$][A-Z0-9_#@$]*$`)
+
+// Names that are not used as parameters of the generated functions
+var reservedParams = map[string]bool{"job": true, "row": true, "rows": true, "err": true, "mapepire": true}
+
+// Represents a table to generate code for
+type model struct {
+	Schema string  // SQL name of the schema
+	Table  string  // SQL name of the table
+	Type   string  // Name of the Go struct
+	Text   string  // Description of the table
+	Fields []field // All columns
+	Key    []field // Primary key columns
+	Insert []field // Columns set by inserts
+	Update []field // Columns set by updates
+}
+
+// Represents a column of a table
+type field struct {
+	Name    string // Name of the Go field
+	Param   string // Name of the Go parameter
+	Column  string // SQL name of the column
+	Select  string // Expression selecting the column
+	GoType  string // Go type of the field
+	Scan    string // Function converting a value of the response
+	Comment string // Comment of the field
+}
+
+// Receive the model of a table
+func newModel(table catalog.Table, columns []catalog.Column, key *catalog.Key) (*model, error) {
+	for _, identifier := range []string{table.Schema, table.Name} {
+		if !ordinaryIdentifier.MatchString(identifier) {
+			return nil, fmt.Errorf("identifier %v requires delimiters, which are not supported", identifier)
+		}
+	}
+
+	m := &model{Schema: table.Schema, Table: table.Name, Type: goName(table.Name, "Table"), Text: singleLine(table.Text)}
+	names := map[string]bool{}
+	fields := map[string]field{}
+	for _, column := range columns {
+		if !ordinaryIdentifier.MatchString(column.Name) {
+			return nil, fmt.Errorf("identifier %v requires delimiters, which are not supported", column.Name)
+		}
+
+		name := goName(column.Name, "Column")
+		if names[name] {
+			name = fmt.Sprintf("%s%d", name, column.Position)
+		}
+		names[name] = true
+
+		goType, scan := goType(column)
+		f := field{Name: name, Param: paramName(name), Column: column.Name, Select: selectExpr(column, goType), GoType: goType, Scan: scan, Comment: comment(column)}
+		m.Fields = append(m.Fields, f)
+		fields[column.Name] = f
+		if !column.Identity {
+			m.Insert = append(m.Insert, f)
+		}
+	}
+
+	if key != nil {
+		isKey := map[string]bool{}
+		for _, column := range key.Columns {
+			f, ok := fields[column]
+			if !ok {
+				return nil, fmt.Errorf("key column %v not found", column)
+			}
+			m.Key = append(m.Key, f)
+			isKey[column] = true
+		}
+		for _, column := range columns {
+			if !isKey[column.Name] && !column.Identity {
+				m.Update = append(m.Update, fields[column.Name])
+			}
+		}
+	}
+	return m, nil
+}
+
+// Generates the formatted source of the models, with the helpers they use if set.
+// Without helpers, the source needs the helpers file in the same package.
+func generate(pkg string, models []model, helpers bool) ([]byte, error) {
+	types := map[string]int{}
+	for i := range models {
+		types[models[i].Type]++
+		if types[models[i].Type] > 1 {
+			models[i].Type = fmt.Sprintf("%s%d", models[i].Type, types[models[i].Type])
+		}
+	}
+
+	var buf bytes.Buffer
+	err := sourceTemplate.Execute(&buf, struct {
+		Package string
+		Models  []model
+		Helpers bool
+	}{pkg, models, helpers})
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated source: %v", err)
+	}
+	return source, nil
+}
+
+// converts a SQL name to an exported Go name, e.g. EMPLOYEE_NUMBER to EmployeeNumber
+func goName(name string, fallback string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + strings.ToLower(part[1:]))
+	}
+
+	goName := b.String()
+	if goName == "" {
+		return fallback
+	}
+	if unicode.IsDigit(rune(goName[0])) {
+		return fallback + goName
+	}
+	return goName
+}
+
+// converts a Go name to a parameter name, e.g. EmployeeNumber to employeeNumber
+func paramName(name string) string {
+	param := lowerFirst(name)
+	if token.IsKeyword(param) || reservedParams[param] {
+		param += "Value"
+	}
+	return param
+}
+
+// receives the Go type of a column and the function converting its values
+func goType(column catalog.Column) (string, string) {
+	var goType, scan string
+	switch strings.ToUpper(column.Type) {
+	case "SMALLINT":
+		goType, scan = "int16", "genNumber[int16]"
+	case "INTEGER":
+		goType, scan = "int32", "genNumber[int32]"
+	case "BIGINT":
+		goType, scan = "int64", "genInt64"
+	case "DECIMAL", "NUMERIC":
+		// decimals that do not fit into an int64 are kept as text to stay exact
+		if column.Scale == 0 && column.Length <= 18 {
+			goType, scan = "int64", "genInt64"
+		} else {
+			goType, scan = "string", "genString"
+		}
+	case "REAL", "DOUBLE", "FLOAT", "DECFLOAT":
+		goType, scan = "float64", "genNumber[float64]"
+	case "CHAR", "GRAPHIC", "NCHAR":
+		goType, scan = "string", "genChar"
+	default:
+		goType, scan = "string", "genString"
+	}
+
+	if !column.Nullable {
+		return goType, scan
+	}
+	if strings.HasPrefix(scan, "genNumber") {
+		return "*" + goType, strings.Replace(scan, "genNumber", "genNumberPtr", 1)
+	}
+	return "*" + goType, scan + "Ptr"
+}
+
+// receives the expression selecting a column. 64-bit integers and decimals
+// are selected as text, as numbers of the response lose precision above 2^53.
+func selectExpr(column catalog.Column, goType string) string {
+	switch strings.TrimPrefix(goType, "*") {
+	case "int64":
+		return fmt.Sprintf("CAST(CAST(%s AS BIGINT) AS VARCHAR(20)) AS %s", column.Name, column.Name)
+	case "string":
+		switch strings.ToUpper(column.Type) {
+		case "DECIMAL", "NUMERIC":
+			// digits, sign and decimal point
+			return fmt.Sprintf("CAST(%s AS VARCHAR(%d)) AS %s", column.Name, column.Length+2, column.Name)
+		}
+	}
+	return column.Name
+}
+
+// receives the comment of a column from its system name, type and description
+func comment(column catalog.Column) string {
+	text := column.Text
+	if text == "" {
+		text = strings.Join(strings.Fields(column.Heading), " ")
+	}
+
+	comment := column.Type
+	if column.Length > 0 {
+		comment += fmt.Sprintf("(%d", column.Length)
+		if column.Scale > 0 {
+			comment += fmt.Sprintf(", %d", column.Scale)
+		}
+		comment += ")"
+	}
+	if column.SystemName != "" && column.SystemName != column.Name {
+		comment = column.SystemName + " " + comment
+	}
+	if text != "" {
+		comment += ": " + text
+	}
+	return singleLine(comment)
+}
+
+// replaces control characters, so the text fits into a line comment
+func singleLine(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// joins the columns of the fields
+func columnList(fields []field, separator string, suffix string) string {
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Column + suffix
+	}
+	return strings.Join(columns, separator)
+}
+
+// joins the select expressions of the fields
+func selectList(fields []field) string {
+	list := make([]string, len(fields))
+	for i, f := range fields {
+		list[i] = f.Select
+	}
+	return strings.Join(list, ", ")
+}
+
+// joins the values of the fields of a row
+func rowValues(fields []field) string {
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		values[i] = "row." + f.Name
+	}
+	return strings.Join(values, ", ")
+}
+
+// joins the parameters of the fields
+func paramList(fields []field, withType bool) string {
+	params := make([]string, len(fields))
+	for i, f := range fields {
+		params[i] = f.Param
+		if withType {
+			params[i] += " " + strings.TrimPrefix(f.GoType, "*")
+		}
+	}
+	return strings.Join(params, ", ")
+}
+
+// joins question marks for the fields
+func markers(fields []field) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", len(fields)), ", ")
+}
+
+// lowers the first letter of a name
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+var sourceTemplate = template.Must(template.New("source").Funcs(template.FuncMap{
+	"columns":    columnList,
+	"selects":    selectList,
+	"values":     rowValues,
+	"params":     paramList,
+	"markers":    markers,
+	"lowerFirst": lowerFirst,
+}).Parse(`// Code generated by mapepire-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Helpers}}
+	"fmt"
+	"strconv"
+	"strings"
+{{end}}
+	mapepire "github.com/deady54/mapepire-go"
+)
+{{range .Models}}{{$type := .Type}}{{$table := printf "%s.%s" .Schema .Table}}
+// {{.Type}} represents a row of {{$table}}{{if .Text}} ({{.Text}}){{end}}
+type {{.Type}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.GoType}} ` + "`" + `db:"{{.Column}}"` + "`" + `{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+}
+
+// Select list of the columns of {{$table}}
+const {{lowerFirst .Type}}Columns = "{{selects .Fields}}"
+
+// converts a row of the response to a {{.Type}}
+func scan{{.Type}}(data map[string]any) {{.Type}} {
+	return {{.Type}}{
+{{- range .Fields}}
+		{{.Name}}: {{.Scan}}(data["{{.Column}}"]),
+{{- end}}
+	}
+}
+
+// Receive the rows of {{$table}} matching the condition (all rows if empty)
+func Select{{.Type}}(job *mapepire.SQLJob, where string, parameters ...any) ([]{{.Type}}, error) {
+	sql := "SELECT " + {{lowerFirst .Type}}Columns + " FROM {{$table}}"
+	if where != "" {
+		sql += " WHERE " + where
+	}
+	data, err := genSelect(job, sql, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]{{.Type}}, len(data))
+	for i, d := range data {
+		rows[i] = scan{{.Type}}(d)
+	}
+	return rows, nil
+}
+{{if .Insert}}
+// Inserts the row into {{$table}}
+func Insert{{.Type}}(job *mapepire.SQLJob, row *{{.Type}}) error {
+	return genExecute(job, "INSERT INTO {{$table}} ({{columns .Insert ", " ""}}) VALUES ({{markers .Insert}})", {{values .Insert}})
+}
+{{end}}{{if .Key}}
+// Receive the row of {{$table}} with the primary key, nil if there is none
+func Get{{.Type}}(job *mapepire.SQLJob, {{params .Key true}}) (*{{.Type}}, error) {
+	rows, err := Select{{.Type}}(job, "{{columns .Key " AND " " = ?"}}", {{params .Key false}})
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+{{if .Update}}
+// Updates the row of {{$table}} with the primary key of the row
+func Update{{.Type}}(job *mapepire.SQLJob, row *{{.Type}}) error {
+	return genExecute(job, "UPDATE {{$table}} SET {{columns .Update ", " " = ?"}} WHERE {{columns .Key " AND " " = ?"}}", {{values .Update}}, {{values .Key}})
+}
+{{end}}
+// Deletes the row of {{$table}} with the primary key
+func Delete{{.Type}}(job *mapepire.SQLJob, {{params .Key true}}) error {
+	return genExecute(job, "DELETE FROM {{$table}} WHERE {{columns .Key " AND " " = ?"}}", {{params .Key false}})
+}
+{{end}}{{end}}{{if .Helpers}}
+// runs a query and receives all rows
+func genSelect(job *mapepire.SQLJob, sql string, parameters []any) ([]map[string]any, error) {
+	options := mapepire.QueryOptions{Rows: mapepire.MAX_FETCH_SIZE}
+	if len(parameters) > 0 {
+		options.Parameters = [][]any{parameters}
+	}
+	query, err := job.QueryWithOptions(sql, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []map[string]any
+	err = query.Stream(func(page *mapepire.ServerResponse) error {
+		data = append(data, page.Data...)
+		return nil
+	})
+	return data, err
+}
+
+// runs a statement with the parameters
+func genExecute(job *mapepire.SQLJob, sql string, parameters ...any) error {
+	query, err := job.QueryWithOptions(sql, mapepire.QueryOptions{Parameters: [][]any{parameters}})
+	if err != nil {
+		return err
+	}
+	_, err = query.Execute()
+	return err
+}
+
+// converts a value to text
+func genString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// converts a value to text, nil if NULL
+func genStringPtr(value any) *string {
+	if value == nil {
+		return nil
+	}
+	v := genString(value)
+	return &v
+}
+
+// converts a value of a fixed-length column to text without trailing blanks
+func genChar(value any) string {
+	return strings.TrimRight(genString(value), " ")
+}
+
+// converts a value of a fixed-length column to text without trailing blanks, nil if NULL
+func genCharPtr(value any) *string {
+	if value == nil {
+		return nil
+	}
+	v := genChar(value)
+	return &v
+}
+
+// converts a value to a number
+func genNumber[T int16 | int32 | float64](value any) T {
+	switch v := value.(type) {
+	case float64:
+		return T(v)
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return T(f)
+	}
+	return 0
+}
+
+// converts a value to a number, nil if NULL
+func genNumberPtr[T int16 | int32 | float64](value any) *T {
+	if value == nil {
+		return nil
+	}
+	v := genNumber[T](value)
+	return &v
+}
+
+// converts a 64-bit integer selected as text
+func genInt64(value any) int64 {
+	switch v := value.(type) {
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// converts a 64-bit integer selected as text, nil if NULL
+func genInt64Ptr(value any) *int64 {
+	if value == nil {
+		return nil
+	}
+	v := genInt64(value)
+	return &v
+}
+{{end}}`))
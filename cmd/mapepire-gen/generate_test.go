@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deady54/mapepire-go/catalog"
+)
+
+func TestGoName(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"EMPLOYEE_NUMBER", "EmployeeNumber"},
+		{"EMPNO", "Empno"},
+		{"ORD#", "Ord"},
+		{"1ST_ADDR", "Column1stAddr"},
+		{"$#@", "Column"},
+	}
+	for _, test := range tests {
+		if have := goName(test.name, "Column"); have != test.want {
+			t.Errorf("have %v, want %v", have, test.want)
+		}
+	}
+}
+
+func TestParamName(t *testing.T) {
+	if have := paramName("Empno"); have != "empno" {
+		t.Errorf("have %v, want empno", have)
+	}
+	if have := paramName("Type"); have != "typeValue" {
+		t.Errorf("have %v, want typeValue", have)
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		column       catalog.Column
+		goType, scan string
+	}{
+		{catalog.Column{Type: "INTEGER"}, "int32", "genNumber[int32]"},
+		{catalog.Column{Type: "DECIMAL", Length: 9, Scale: 2, Nullable: true}, "*string", "genStringPtr"},
+		{catalog.Column{Type: "NUMERIC", Length: 31}, "string", "genString"},
+		{catalog.Column{Type: "NUMERIC", Length: 7}, "int64", "genInt64"},
+		{catalog.Column{Type: "BIGINT", Nullable: true}, "*int64", "genInt64Ptr"},
+		{catalog.Column{Type: "CHAR", Length: 6}, "string", "genChar"},
+		{catalog.Column{Type: "VARCHAR", Length: 60, Nullable: true}, "*string", "genStringPtr"},
+		{catalog.Column{Type: "DATE"}, "string", "genString"},
+	}
+	for _, test := range tests {
+		goType, scan := goType(test.column)
+		if goType != test.goType || scan != test.scan {
+			t.Errorf("have %v %v, want %v %v", goType, scan, test.goType, test.scan)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	table := catalog.Table{Schema: "SAMPLE", Name: "DEPARTMENT", Text: "Departments"}
+	columns := []catalog.Column{
+		{Name: "DEPTNO", SystemName: "DEPTNO", Position: 1, Type: "CHAR", Length: 3},
+		{Name: "DEPTNAME", SystemName: "DEPTNAME", Position: 2, Type: "VARCHAR", Length: 36, Text: "Name of the department"},
+		{Name: "MGRNO", SystemName: "MGRNO", Position: 3, Type: "CHAR", Length: 6, Nullable: true},
+	}
+	m, err := newModel(table, columns, &catalog.Key{Type: catalog.CONSTRAINT_PRIMARY_KEY, Columns: []string{"DEPTNO"}})
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+
+	source, err := generate("sample", []model{*m}, false)
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	for _, want := range []string{
+		"type Department struct {",
+		"`db:\"DEPTNAME\"` // VARCHAR(36): Name of the department",
+		"Mgrno    *string `db:\"MGRNO\"`",
+		`"INSERT INTO SAMPLE.DEPARTMENT (DEPTNO, DEPTNAME, MGRNO) VALUES (?, ?, ?)"`,
+		"func GetDepartment(job *mapepire.SQLJob, deptno string) (*Department, error) {",
+		`"UPDATE SAMPLE.DEPARTMENT SET DEPTNAME = ?, MGRNO = ? WHERE DEPTNO = ?", row.Deptname, row.Mgrno, row.Deptno`,
+		`"DELETE FROM SAMPLE.DEPARTMENT WHERE DEPTNO = ?", deptno`,
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("generated source should contain %q", want)
+		}
+	}
+	if strings.Contains(string(source), "func genSelect") {
+		t.Errorf("generated source should not contain the helpers")
+	}
+
+	helpers, err := generate("sample", nil, true)
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	if !strings.Contains(string(helpers), "func genSelect") || !strings.Contains(string(helpers), "func genInt64(") {
+		t.Errorf("helpers should contain genSelect and genInt64")
+	}
+}
+
+func TestSelectExpr(t *testing.T) {
+	if have := selectExpr(catalog.Column{Name: "ID", Type: "BIGINT"}, "*int64"); have != "CAST(CAST(ID AS BIGINT) AS VARCHAR(20)) AS ID" {
+		t.Errorf("have %v, want ID selected as text", have)
+	}
+	if have := selectExpr(catalog.Column{Name: "SALARY", Type: "DECIMAL", Length: 9, Scale: 2}, "string"); have != "CAST(SALARY AS VARCHAR(11)) AS SALARY" {
+		t.Errorf("have %v, want SALARY selected as text", have)
+	}
+	if have := selectExpr(catalog.Column{Name: "NAME", Type: "VARCHAR"}, "string"); have != "NAME" {
+		t.Errorf("have %v, want NAME", have)
+	}
+}
+
+func TestNewModelDelimited(t *testing.T) {
+	_, err := newModel(catalog.Table{Schema: "SAMPLE", Name: "lower"}, nil, nil)
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
@@ -0,0 +1,153 @@
+// Command mapepire-gen generates Go structs and CRUD functions for tables
+// of an IBM i database, using the catalog of the server.
+//
+// Usage:
+//
+//	mapepire-gen -host myibmi -user me -schema SAMPLE -tables EMPLOYEE,DEPARTMENT -package sample -out sample.go
+//
+// The password is read from the MAPEPIRE_PASSWORD environment variable unless
+// it is given with -password. With -out, the helpers used by the generated code
+// are written to mapepire_helpers.go next to the output file, so several output
+// files can share a package. Without -out, a single file including the helpers
+// is written to stdout.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mapepire "github.com/deady54/mapepire-go"
+	"github.com/deady54/mapepire-go/catalog"
+)
+
+func main() {
+	host := flag.String("host", "", "hostname or IP of the server")
+	port := flag.String("port", "", "port of the server (default 8076)")
+	user := flag.String("user", "", "user for authentication")
+	password := flag.String("password", os.Getenv("MAPEPIRE_PASSWORD"), "password for authentication (default $MAPEPIRE_PASSWORD)")
+	ignoreUnauthorized := flag.Bool("ignore-unauthorized", false, "ignore unauthorized certificates")
+	schema := flag.String("schema", "", "schema of the tables")
+	tables := flag.String("tables", "", "comma-separated tables or LIKE patterns (all tables if empty)")
+	pkg := flag.String("package", "models", "name of the generated package")
+	out := flag.String("out", "", "output file (stdout if empty)")
+	flag.Parse()
+
+	if *host == "" || *user == "" || *schema == "" {
+		fmt.Fprintln(os.Stderr, "mapepire-gen: -host, -user and -schema required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	server := mapepire.DaemonServer{
+		Host:               *host,
+		Port:               *port,
+		User:               *user,
+		Password:           *password,
+		IgnoreUnauthorized: *ignoreUnauthorized,
+	}
+	err := run(server, strings.ToUpper(*schema), patterns(*tables), *pkg, *out)
+	if err != nil {
+		fail(err)
+	}
+}
+
+// generates the code for the tables, the job is closed before returning
+func run(server mapepire.DaemonServer, schema string, patterns []string, pkg string, out string) error {
+	job := mapepire.NewSQLJob("mapepire-gen")
+	err := job.Connect(server)
+	if err != nil {
+		return err
+	}
+	defer job.Close()
+
+	models, err := readModels(catalog.New(job), schema, patterns)
+	if err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("no tables found in %v", schema)
+	}
+
+	if out == "" {
+		source, err := generate(pkg, models, true)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	source, err := generate(pkg, models, false)
+	if err != nil {
+		return err
+	}
+	helpers, err := generate(pkg, nil, true)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(out, source, 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(filepath.Dir(out), helpersFile), helpers, 0644)
+}
+
+// reads the tables matching the patterns from the catalog
+func readModels(cat *catalog.Catalog, schema string, patterns []string) ([]model, error) {
+	var models []model
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		tables, err := cat.Tables(schema, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, table := range tables {
+			if seen[table.Name] {
+				continue
+			}
+			seen[table.Name] = true
+
+			columns, err := cat.Columns(table.Schema, table.Name)
+			if err != nil {
+				return nil, err
+			}
+			key, err := cat.PrimaryKey(table.Schema, table.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			m, err := newModel(table, columns, key)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "mapepire-gen: skipping %v.%v: %v\n", table.Schema, table.Name, err)
+				continue
+			}
+			models = append(models, *m)
+		}
+	}
+	return models, nil
+}
+
+// splits the comma-separated patterns, all tables if empty
+func patterns(tables string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(tables, ",") {
+		pattern = strings.ToUpper(strings.TrimSpace(pattern))
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return []string{""}
+	}
+	return patterns
+}
+
+// prints the error and exits
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "mapepire-gen:", err)
+	os.Exit(1)
+}
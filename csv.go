@@ -0,0 +1,119 @@
+package mapepire
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Represents options for the CSV export
+type CSVOptions struct {
+	Delimiter       rune   // Field delimiter, comma if not set
+	QuoteAll        bool   // Whether to quote all fields, or only those that need it
+	NullValue       string // Representation of NULL values
+	NoHeader        bool   // Whether to omit the header with the column names
+	UseCRLF         bool   // Whether to end lines with \r\n instead of \n
+	DateFormat      string // Go time layout for DATE columns, unchanged if not set
+	TimeFormat      string // Go time layout for TIME columns, unchanged if not set
+	TimestampFormat string // Go time layout for TIMESTAMP columns, unchanged if not set
+}
+
+// Executes the query and writes all rows as CSV.
+// The rows are fetched page by page, so the result never sits in memory.
+func ExportCSV(w io.Writer, query *Query, options CSVOptions) error {
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	buf := bufio.NewWriter(w)
+
+	header := !options.NoHeader
+	err := query.Stream(func(page *ServerResponse) error {
+		if page.Metadata == nil {
+			return nil
+		}
+		columns := page.Metadata.Columns
+
+		if header {
+			names := make([]string, len(columns))
+			for i, column := range columns {
+				names[i] = column.Name
+			}
+			err := writeCSVRecord(buf, names, options)
+			if err != nil {
+				return err
+			}
+			header = false
+		}
+
+		for _, row := range page.Rows() {
+			record := make([]string, len(row))
+			for i, value := range row {
+				record[i] = csvValue(value, columns[i].Type, options)
+			}
+			err := writeCSVRecord(buf, record, options)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// Runs the SQL and writes all rows as CSV
+func (s *SQLJob) ExportCSV(w io.Writer, sql string, options CSVOptions) error {
+	query, err := s.QueryWithOptions(sql, QueryOptions{Rows: MAX_FETCH_SIZE})
+	if err != nil {
+		return err
+	}
+	return ExportCSV(w, query, options)
+}
+
+// formats a value for the CSV export
+func csvValue(value any, columnType string, options CSVOptions) string {
+	if value == nil {
+		return options.NullValue
+	}
+
+	text := formatValue(value)
+	var layout string
+	switch strings.ToUpper(columnType) {
+	case "DATE":
+		layout = options.DateFormat
+	case "TIME":
+		layout = options.TimeFormat
+	case "TIMESTAMP":
+		layout = options.TimestampFormat
+	}
+	if layout != "" {
+		if t, ok := parseTemporal(columnType, text); ok {
+			return t.Format(layout)
+		}
+	}
+	return text
+}
+
+// writes a single CSV record
+func writeCSVRecord(w *bufio.Writer, record []string, options CSVOptions) error {
+	for i, field := range record {
+		if i > 0 {
+			w.WriteRune(options.Delimiter)
+		}
+
+		quote := options.QuoteAll || strings.ContainsRune(field, options.Delimiter) || strings.ContainsAny(field, "\"\r\n")
+		if !quote {
+			w.WriteString(field)
+			continue
+		}
+		w.WriteByte('"')
+		w.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		w.WriteByte('"')
+	}
+	if options.UseCRLF {
+		w.WriteByte('\r')
+	}
+	return w.WriteByte('\n')
+}
@@ -0,0 +1,61 @@
+package mapepire
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSVRecord(t *testing.T) {
+	tests := []struct {
+		record  []string
+		options CSVOptions
+		want    string
+	}{
+		{[]string{"1", "Max"}, CSVOptions{Delimiter: ','}, "1,Max\n"},
+		{[]string{"1", "a,b", `say "hi"`}, CSVOptions{Delimiter: ','}, "1,\"a,b\",\"say \"\"hi\"\"\"\n"},
+		{[]string{"1", "Max"}, CSVOptions{Delimiter: ';', QuoteAll: true, UseCRLF: true}, "\"1\";\"Max\"\r\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeCSVRecord(w, test.record, test.options)
+		w.Flush()
+		if buf.String() != test.want {
+			t.Errorf("have %q, want %q", buf.String(), test.want)
+		}
+	}
+}
+
+func TestCSVValue(t *testing.T) {
+	options := CSVOptions{NullValue: "NULL", DateFormat: "02.01.2006"}
+	if have := csvValue(nil, "VARCHAR", options); have != "NULL" {
+		t.Errorf("have %v, want NULL", have)
+	}
+	if have := csvValue("2024-01-31", "DATE", options); have != "31.01.2024" {
+		t.Errorf("have %v, want 31.01.2024", have)
+	}
+	if have := csvValue(12.5, "DECIMAL", options); have != "12.5" {
+		t.Errorf("have %v, want 12.5", have)
+	}
+}
+
+// Export a query as CSV
+func TestExportCSV(t *testing.T) {
+	job, _ := initSQLTable("SELECT * FROM TEMPTEST", QueryOptions{})
+
+	var buf bytes.Buffer
+	err := job.ExportCSV(&buf, "SELECT ID, DESCRIPTION FROM TEMPTEST ORDER BY ID", CSVOptions{})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Errorf("have %v lines, want 6", len(lines))
+	}
+	if lines[0] != "ID,DESCRIPTION" {
+		t.Errorf("have %v, want ID,DESCRIPTION", lines[0])
+	}
+}
@@ -19,3 +19,39 @@ type ServerError struct {
 func (e *ServerError) Error() string {
 	return fmt.Sprintf("server error in %v method: %v", e.Method, e.Message)
 }
+
+type CircuitOpenError struct {
+	Method  string
+	Message string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open in %v method: %v", e.Method, e.Message)
+}
+
+type CancelError struct {
+	Method  string
+	Message string
+}
+
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("query canceled in %v method: %v", e.Method, e.Message)
+}
+
+type TimeoutError struct {
+	Method  string
+	Message string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("query time limit exceeded in %v method: %v", e.Method, e.Message)
+}
+
+type PoolExhaustedError struct {
+	Method  string
+	Message string
+}
+
+func (e *PoolExhaustedError) Error() string {
+	return fmt.Sprintf("pool exhausted in %v method: %v", e.Method, e.Message)
+}
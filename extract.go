@@ -0,0 +1,324 @@
+package mapepire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The amount of pages buffered per partition
+const EXTRACT_BUFFER_SIZE = 4
+
+// Represents options for a partitioned extraction
+type ExtractOptions struct {
+	Schema      string   // Schema of the table
+	Table       string   // Table to extract
+	Columns     []string // Column names to select, all if empty
+	Where       string   // Predicate applied to all partitions, if any
+	Partitions  int      // The amount of RRN or key ranges (MaxSize of the pool if not set)
+	Key         string   // Column to split into key ranges, RRN ranges if empty
+	Predicates  []string // Predicates of the partitions, replaces RRN and key ranges
+	Parallelism int      // The amount of partitions read concurrently, at most MaxSize of the pool (MaxSize if not set)
+	Ordered     bool     // Whether pages are passed in order of the partitions (and keys or RRNs)
+	Rows        int      // The amount of rows to fetch per request
+}
+
+// Represents a range of a table
+type partition struct {
+	predicate  string // Predicate selecting the rows of the partition
+	parameters []any  // Parameters of the predicate
+}
+
+// Reads a table split into partitions concurrently with jobs of the pool and
+// passes the pages of all partitions to fn. fn is never called concurrently.
+// Unless ordered, pages are passed as soon as they are received.
+// If fn returns an error, the extraction is stopped and the error is returned.
+func (jp *JobPool) Extract(ctx context.Context, options ExtractOptions, fn func(page *ServerResponse) error) error {
+	if options.Schema == "" || options.Table == "" {
+		return fmt.Errorf("schema and table required")
+	}
+	options.Schema, options.Table = strings.ToUpper(options.Schema), strings.ToUpper(options.Table)
+	options.Key = strings.ToUpper(options.Key)
+	options.Columns = append([]string(nil), options.Columns...)
+	for i := range options.Columns {
+		options.Columns[i] = strings.ToUpper(options.Columns[i])
+	}
+	identifiers := append([]string{options.Schema, options.Table}, options.Columns...)
+	if options.Key != "" {
+		identifiers = append(identifiers, options.Key)
+	}
+	for _, identifier := range identifiers {
+		if !ordinaryIdentifier.MatchString(identifier) {
+			return fmt.Errorf("identifier %v requires delimiters, which are not supported", identifier)
+		}
+	}
+	if options.Partitions <= 0 {
+		options.Partitions = jp.options.MaxSize
+	}
+	if options.Parallelism <= 0 || options.Parallelism > jp.options.MaxSize {
+		// further partitions would only wait for the pool and time out
+		options.Parallelism = jp.options.MaxSize
+	}
+	if options.Rows <= 0 {
+		options.Rows = MAX_FETCH_SIZE
+	}
+
+	partitions, err := jp.partitions(ctx, options)
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	channels := make([]chan *ServerResponse, len(partitions))
+	for i := range channels {
+		channels[i] = make(chan *ServerResponse, EXTRACT_BUFFER_SIZE)
+	}
+	errs := make(chan error, len(partitions))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(partitions))
+
+	// partitions are started in order, so the first unfinished one is always running
+	go func() {
+		slots := make(chan struct{}, options.Parallelism)
+		for i, part := range partitions {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				for j := i; j < len(partitions); j++ {
+					close(channels[j])
+					wg.Done()
+				}
+				return
+			}
+			go func(part partition, pages chan<- *ServerResponse) {
+				defer func() { <-slots }()
+				defer wg.Done()
+				defer close(pages)
+				err := jp.extractPartition(ctx, options, part, pages)
+				if err != nil {
+					errs <- err
+					cancel()
+				}
+			}(part, channels[i])
+		}
+	}()
+
+	var fnErr error
+	consume := func(page *ServerResponse) {
+		if fnErr != nil {
+			return
+		}
+		fnErr = fn(page)
+		if fnErr != nil {
+			cancel()
+		}
+	}
+
+	if options.Ordered {
+		for _, pages := range channels {
+			for page := range pages {
+				consume(page)
+			}
+		}
+	} else {
+		merged := make(chan *ServerResponse)
+		mergeWg := new(sync.WaitGroup)
+		mergeWg.Add(len(channels))
+		for _, pages := range channels {
+			go func(pages <-chan *ServerResponse) {
+				defer mergeWg.Done()
+				for page := range pages {
+					merged <- page
+				}
+			}(pages)
+		}
+		go func() {
+			mergeWg.Wait()
+			close(merged)
+		}()
+		for page := range merged {
+			consume(page)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	if fnErr != nil {
+		return fnErr
+	}
+	// partitions stopped by the first failing one report the cancellation
+	var extractErr error
+	for err := range errs {
+		if extractErr == nil || errors.Is(extractErr, context.Canceled) {
+			extractErr = err
+		}
+	}
+	return extractErr
+}
+
+// reads a partition with a job of the pool and sends its pages
+func (jp *JobPool) extractPartition(ctx context.Context, options ExtractOptions, part partition, pages chan<- *ServerResponse) error {
+	return jp.withJob(ctx, PRIORITY_BATCH, func(job *SQLJob) error {
+		queryOptions := QueryOptions{Rows: options.Rows}
+		if len(part.parameters) > 0 {
+			queryOptions.Parameters = [][]any{part.parameters}
+		}
+		query, err := job.QueryWithOptions(extractSQL(options, part), queryOptions)
+		if err != nil {
+			return err
+		}
+
+		return query.Stream(func(page *ServerResponse) error {
+			select {
+			case pages <- page:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	})
+}
+
+// splits the table into partitions
+func (jp *JobPool) partitions(ctx context.Context, options ExtractOptions) ([]partition, error) {
+	if len(options.Predicates) > 0 {
+		partitions := make([]partition, len(options.Predicates))
+		for i, predicate := range options.Predicates {
+			partitions[i] = partition{predicate: predicate}
+		}
+		return partitions, nil
+	}
+
+	var partitions []partition
+	err := jp.withJob(ctx, PRIORITY_BATCH, func(job *SQLJob) error {
+		var err error
+		if options.Key != "" {
+			partitions, err = job.keyPartitions(options)
+		} else {
+			partitions, err = job.rrnPartitions(options)
+		}
+		return err
+	})
+	return partitions, err
+}
+
+// splits the table into ranges of relative record numbers of equal size
+func (s *SQLJob) rrnPartitions(options ExtractOptions) ([]partition, error) {
+	query, err := s.QueryWithOptions(
+		"SELECT SUM(NUMBER_ROWS + NUMBER_DELETED_ROWS) AS TOTAL FROM QSYS2.SYSTABLESTAT WHERE TABLE_SCHEMA = ? AND (TABLE_NAME = ? OR SYSTEM_TABLE_NAME = ?)",
+		QueryOptions{Rows: 1, Parameters: [][]any{{options.Schema, options.Table, options.Table}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := query.Execute()
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 || resp.Data[0]["TOTAL"] == nil {
+		return nil, fmt.Errorf("table %v.%v not found", options.Schema, options.Table)
+	}
+	total, err := strconv.ParseInt(formatValue(resp.Data[0]["TOTAL"]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return rrnRanges(total, options.Partitions), nil
+}
+
+// splits the relative record numbers 1 to total into ranges
+func rrnRanges(total int64, count int) []partition {
+	if total <= 0 {
+		return nil
+	}
+	if int64(count) > total {
+		count = int(total)
+	}
+
+	size := (total + int64(count) - 1) / int64(count)
+	partitions := make([]partition, 0, count)
+	for start := int64(1); start <= total; start += size {
+		if start+size > total {
+			partitions = append(partitions, partition{predicate: "RRN(T) >= ?", parameters: []any{start}})
+		} else {
+			partitions = append(partitions, partition{predicate: "RRN(T) BETWEEN ? AND ?", parameters: []any{start, start + size - 1}})
+		}
+	}
+	return partitions
+}
+
+// splits the table into key ranges with about the same amount of rows.
+// The bounds are received as text, so numbers keep their precision.
+func (s *SQLJob) keyPartitions(options ExtractOptions) ([]partition, error) {
+	sql := fmt.Sprintf(
+		"SELECT VARCHAR(MIN(K)) AS BOUND FROM (SELECT %s AS K, NTILE(%d) OVER (ORDER BY %s) AS P FROM %s.%s WHERE %s IS NOT NULL) X GROUP BY P ORDER BY P",
+		options.Key, options.Partitions, options.Key, options.Schema, options.Table, options.Key,
+	)
+	query, err := s.QueryWithOptions(sql, QueryOptions{Rows: MAX_FETCH_SIZE})
+	if err != nil {
+		return nil, err
+	}
+
+	var bounds []any
+	err = query.Stream(func(page *ServerResponse) error {
+		for _, row := range page.Data {
+			bounds = append(bounds, row["BOUND"])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keyRanges(options.Key, bounds), nil
+}
+
+// builds the key ranges from the lower bounds. Rows with a NULL key are part
+// of the last range, as NULL values are sorted last.
+func keyRanges(key string, bounds []any) []partition {
+	if len(bounds) == 0 {
+		return []partition{{predicate: key + " IS NULL"}}
+	}
+
+	partitions := make([]partition, len(bounds))
+	for i := range bounds {
+		switch {
+		case len(bounds) == 1:
+			partitions[i] = partition{predicate: "1 = 1"}
+		case i == 0:
+			partitions[i] = partition{predicate: key + " < ?", parameters: []any{bounds[1]}}
+		case i == len(bounds)-1:
+			partitions[i] = partition{predicate: fmt.Sprintf("(%s >= ? OR %s IS NULL)", key, key), parameters: []any{bounds[i]}}
+		default:
+			partitions[i] = partition{predicate: fmt.Sprintf("%s >= ? AND %s < ?", key, key), parameters: []any{bounds[i], bounds[i+1]}}
+		}
+	}
+	return partitions
+}
+
+// builds the query of a partition
+func extractSQL(options ExtractOptions, part partition) string {
+	columns := "T.*"
+	if len(options.Columns) > 0 {
+		columns = strings.Join(options.Columns, ", ")
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s.%s T WHERE (%s)", columns, options.Schema, options.Table, part.predicate)
+	if options.Where != "" {
+		sql += fmt.Sprintf(" AND (%s)", options.Where)
+	}
+	if options.Ordered {
+		if options.Key != "" && len(options.Predicates) == 0 {
+			sql += " ORDER BY " + options.Key
+		} else if options.Key == "" && len(options.Predicates) == 0 {
+			sql += " ORDER BY RRN(T)"
+		}
+	}
+	return sql
+}
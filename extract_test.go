@@ -0,0 +1,105 @@
+package mapepire
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRRNRanges(t *testing.T) {
+	partitions := rrnRanges(10, 3)
+	if len(partitions) != 3 {
+		t.Fatalf("have %v partitions, want 3", len(partitions))
+	}
+	if partitions[0].predicate != "RRN(T) BETWEEN ? AND ?" || partitions[0].parameters[0] != int64(1) || partitions[0].parameters[1] != int64(4) {
+		t.Errorf("have %v, want RRN 1 to 4", partitions[0])
+	}
+	if partitions[2].predicate != "RRN(T) >= ?" || partitions[2].parameters[0] != int64(9) {
+		t.Errorf("have %v, want RRN from 9", partitions[2])
+	}
+
+	if len(rrnRanges(2, 5)) != 2 {
+		t.Errorf("should not create empty partitions")
+	}
+	if rrnRanges(0, 5) != nil {
+		t.Errorf("should not create partitions for an empty table")
+	}
+}
+
+func TestKeyRanges(t *testing.T) {
+	partitions := keyRanges("ID", []any{"1", "9007199254740993", "9007199254740995"})
+	want := []string{"ID < ?", "ID >= ? AND ID < ?", "(ID >= ? OR ID IS NULL)"}
+	for i, part := range partitions {
+		if part.predicate != want[i] {
+			t.Errorf("have %v, want %v", part.predicate, want[i])
+		}
+	}
+	if partitions[1].parameters[0] != "9007199254740993" || partitions[1].parameters[1] != "9007199254740995" {
+		t.Errorf("have %v, want the bounds unchanged", partitions[1].parameters)
+	}
+}
+
+func TestExtractSQL(t *testing.T) {
+	options := ExtractOptions{Schema: "SAMPLE", Table: "SALES", Columns: []string{"REGION", "SALES"}, Where: "SALES > 0", Ordered: true}
+	have := extractSQL(options, partition{predicate: "RRN(T) >= ?"})
+	want := "SELECT REGION, SALES FROM SAMPLE.SALES T WHERE (RRN(T) >= ?) AND (SALES > 0) ORDER BY RRN(T)"
+	if have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+// Extract with identifiers that would need delimiters
+func TestExtractInvalidIdentifier(t *testing.T) {
+	pool := &JobPool{}
+	options := []ExtractOptions{
+		{Schema: "SAMPLE", Table: "EMPLOYEE", Key: "ID; DROP TABLE X"},
+		{Schema: "SAMPLE", Table: "EMPLOYEE", Columns: []string{"EMPNO", "1 FROM QSYS2.SYSTABLES --"}},
+	}
+	for _, option := range options {
+		err := pool.Extract(context.Background(), option, func(page *ServerResponse) error { return nil })
+		if err == nil {
+			t.Errorf("should throw error for %+v", option)
+		}
+	}
+}
+
+// Extract a table in partitions
+func TestExtract(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxSize: 3, StartingSize: 3})
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	defer pool.Close()
+
+	rows := 0
+	err = pool.Extract(context.Background(), ExtractOptions{Schema: "SAMPLE", Table: "EMPLOYEE", Partitions: 3, Ordered: true}, func(page *ServerResponse) error {
+		rows += len(page.Data)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("should not throw error: %v", err)
+	}
+	if rows == 0 {
+		t.Errorf("should receive rows")
+	}
+}
+
+// Extract with more parallel partitions than jobs in the pool
+func TestExtractParallelismAboveMaxSize(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxSize: 2, StartingSize: 1})
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	defer pool.Close()
+
+	rows := 0
+	err = pool.Extract(context.Background(), ExtractOptions{Schema: "SAMPLE", Table: "EMPLOYEE", Partitions: 8, Parallelism: 8}, func(page *ServerResponse) error {
+		rows += len(page.Data)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("should not throw error: %v", err)
+	}
+	if rows == 0 {
+		t.Errorf("should receive rows")
+	}
+}
@@ -0,0 +1,100 @@
+package mapepire
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DEFAULT_TARGET_BYTES   = 1 << 20 // Default byte budget per message of adaptive fetching
+	DEFAULT_TARGET_LATENCY = 2       // Default round-trip time per message of adaptive fetching (in seconds)
+	MAX_ADAPTIVE_FETCH     = 50000   // Upper limit of rows per request of adaptive fetching
+)
+
+// Max factor the fetch size grows by per request
+const adaptiveGrowth = 4
+
+// Weight of the latest page in the average row size
+const adaptiveWeight = 0.5
+
+// Adjusts the rows per request to the size and round-trip time of the pages
+type adaptiveFetcher struct {
+	targetBytes   int           // Byte budget per message
+	targetLatency time.Duration // Round-trip time per message
+	rows          int           // Rows of the next request
+	rowSize       float64       // Average size of a row (in bytes)
+}
+
+// Receive a new adaptive fetcher
+func newAdaptiveFetcher(rows int, targetBytes int, targetLatency int) *adaptiveFetcher {
+	if rows <= 0 {
+		rows = DEFAULT_FETCH_SIZE
+	} else if rows > MAX_ADAPTIVE_FETCH {
+		rows = MAX_ADAPTIVE_FETCH
+	}
+	if targetBytes <= 0 {
+		targetBytes = DEFAULT_TARGET_BYTES
+	}
+	if targetLatency <= 0 {
+		targetLatency = DEFAULT_TARGET_LATENCY
+	}
+	return &adaptiveFetcher{targetBytes: targetBytes, targetLatency: time.Duration(targetLatency) * time.Second, rows: rows}
+}
+
+// observes a page and adjusts the rows of the next request
+func (a *adaptiveFetcher) observe(rows int, size int, latency time.Duration) {
+	if rows <= 0 || size <= 0 {
+		return
+	}
+
+	rowSize := float64(size) / float64(rows)
+	if a.rowSize == 0 {
+		a.rowSize = rowSize
+	} else {
+		a.rowSize = adaptiveWeight*rowSize + (1-adaptiveWeight)*a.rowSize
+	}
+
+	next := int(float64(a.targetBytes) / a.rowSize)
+	if latency > a.targetLatency {
+		slower := int(float64(rows) * float64(a.targetLatency) / float64(latency))
+		if slower < next {
+			next = slower
+		}
+	}
+
+	if next > a.rows*adaptiveGrowth {
+		next = a.rows * adaptiveGrowth
+	}
+	if next > MAX_ADAPTIVE_FETCH {
+		next = MAX_ADAPTIVE_FETCH
+	}
+	if next < 1 {
+		next = 1
+	}
+	a.rows = next
+}
+
+// Fetch the next rows of the query. With adaptive fetching, the amount of rows
+// is adjusted to the previous pages, otherwise the rows of the query are fetched.
+func (q *Query) FetchNext() (*ServerResponse, error) {
+	rows := q.rowsToFetch
+	if q.adaptive != nil {
+		rows = fmt.Sprint(q.adaptive.rows)
+	} else if rows == "" {
+		rows = fmt.Sprint(DEFAULT_FETCH_SIZE)
+	}
+
+	return q.FetchMore(q.ID, rows)
+}
+
+// passes a page to the adaptive fetcher, if any
+func (q *Query) observe(resp *ServerResponse) {
+	if q.adaptive == nil {
+		return
+	}
+	rows := len(resp.Data)
+	if resp.TerseData != nil {
+		rows = len(resp.TerseData)
+	}
+	q.adaptive.observe(rows, resp.size, resp.latency)
+}
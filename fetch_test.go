@@ -0,0 +1,64 @@
+package mapepire
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveFetcherNarrowRows(t *testing.T) {
+	a := newAdaptiveFetcher(100, 1000, 0)
+	a.observe(100, 100, time.Millisecond)
+	if a.rows != 400 {
+		t.Errorf("have %v, want 400 (growth is limited)", a.rows)
+	}
+	a.observe(400, 1200, time.Millisecond)
+	if a.rows != 500 {
+		t.Errorf("have %v, want 500", a.rows)
+	}
+}
+
+func TestAdaptiveFetcherWideRows(t *testing.T) {
+	a := newAdaptiveFetcher(100, 1000, 0)
+	a.observe(100, 100000, time.Millisecond)
+	if a.rows != 1 {
+		t.Errorf("have %v, want 1", a.rows)
+	}
+}
+
+func TestAdaptiveFetcherLatency(t *testing.T) {
+	a := newAdaptiveFetcher(100, 1<<20, 1)
+	a.observe(100, 1000, 4*time.Second)
+	if a.rows != 25 {
+		t.Errorf("have %v, want 25", a.rows)
+	}
+}
+
+func TestAdaptiveFetcherLimit(t *testing.T) {
+	a := newAdaptiveFetcher(MAX_ADAPTIVE_FETCH*2, 1<<30, 0)
+	if a.rows != MAX_ADAPTIVE_FETCH {
+		t.Errorf("have %v, want %v", a.rows, MAX_ADAPTIVE_FETCH)
+	}
+	a.observe(a.rows, a.rows, time.Millisecond)
+	if a.rows != MAX_ADAPTIVE_FETCH {
+		t.Errorf("have %v, want %v", a.rows, MAX_ADAPTIVE_FETCH)
+	}
+}
+
+// Stream with adaptive fetching
+func TestStreamAdaptive(t *testing.T) {
+	job := NewSQLJob("test")
+	job.Connect(server)
+
+	query, _ := job.QueryWithOptions("SELECT * FROM SAMPLE.EMPLOYEE", QueryOptions{Rows: 5, AdaptiveFetch: true, TargetBytes: 4096})
+	rows := 0
+	err := query.Stream(func(page *ServerResponse) error {
+		rows += len(page.Data)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("should not throw error: %v", err)
+	}
+	if rows == 0 {
+		t.Errorf("should receive rows")
+	}
+}
@@ -0,0 +1,116 @@
+package mapepire
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	HOST_STRATEGY_FAILOVER    = "FAILOVER"
+	HOST_STRATEGY_ROUND_ROBIN = "ROUND_ROBIN"
+	HOST_STRATEGY_LEAST_BUSY  = "LEAST_BUSY"
+)
+const DEFAULT_HOST_RETRY_TIME = 30
+
+// Represents the hosts a pool connects to
+type hostList struct {
+	hosts []*host       // All hosts, in the order of the pool options
+	next  atomic.Uint32 // The next host for round-robin
+	lock  sync.Mutex    // Mutex
+}
+
+// Represents a single host
+type host struct {
+	server    DaemonServer // Connection details of the host
+	downUntil time.Time    // Until when the host is marked down
+}
+
+// Receive a new host list
+func newHostList(servers []DaemonServer) *hostList {
+	list := &hostList{}
+	for _, server := range servers {
+		list.hosts = append(list.hosts, &host{server: server})
+	}
+	return list
+}
+
+// Receive the hosts in the order they should be tried.
+// Hosts that are marked down come last, so they are probed again
+// if none of the other hosts can be reached.
+func (jp *JobPool) hostOrder() []*host {
+	hl := jp.hosts
+	hosts := make([]*host, len(hl.hosts))
+	copy(hosts, hl.hosts)
+
+	switch jp.options.HostStrategy {
+	case HOST_STRATEGY_ROUND_ROBIN:
+		start := int(hl.next.Add(1)-1) % len(hosts)
+		hosts = append(hosts[start:], hosts[:start]...)
+	case HOST_STRATEGY_LEAST_BUSY:
+		busy := jp.busyHosts()
+		sort.SliceStable(hosts, func(i, j int) bool {
+			return busy[hostKey(hosts[i].server)] < busy[hostKey(hosts[j].server)]
+		})
+	}
+
+	now := time.Now()
+	hl.lock.Lock()
+	defer hl.lock.Unlock()
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return !hosts[i].downUntil.After(now) && hosts[j].downUntil.After(now)
+	})
+	return hosts
+}
+
+// connects a job to the first host that can be reached
+// and marks unreachable hosts down
+func (jp *JobPool) connectHost(s *SQLJob) error {
+	var errs error
+	for _, h := range jp.hostOrder() {
+		err := s.Connect(h.server)
+		if err == nil {
+			jp.hosts.lock.Lock()
+			h.downUntil = time.Time{}
+			jp.hosts.lock.Unlock()
+			return nil
+		}
+		errs = errors.Join(errs, err)
+		if s.connection != nil {
+			s.connection.Close()
+			s.connection = nil
+		}
+
+		retry := jp.options.HostRetryTime
+		if retry <= 0 {
+			retry = DEFAULT_HOST_RETRY_TIME
+		}
+		jp.hosts.lock.Lock()
+		h.downUntil = time.Now().Add(time.Duration(retry) * time.Second)
+		jp.hosts.lock.Unlock()
+	}
+	return errs
+}
+
+// Receive the number of borrowed jobs per host
+func (jp *JobPool) busyHosts() map[string]int {
+	jp.borrows.lock.Lock()
+	defer jp.borrows.lock.Unlock()
+
+	busy := make(map[string]int)
+	for job := range jp.borrows.list {
+		busy[hostKey(job.daemon)]++
+	}
+	return busy
+}
+
+// Receive the key identifying a host
+func hostKey(server DaemonServer) string {
+	port := server.Port
+	if port == "" {
+		port = "8076"
+	}
+	return server.Host + ":" + port
+}
@@ -0,0 +1,83 @@
+package mapepire
+
+import (
+	"testing"
+	"time"
+)
+
+func newHostPool(t *testing.T, strategy string) *JobPool {
+	pool, err := NewPool(PoolOptions{
+		Hosts: []DaemonServer{
+			{Host: "127.0.0.1", Port: "1", User: "user", Password: "pw"},
+			{Host: "127.0.0.2", Port: "1", User: "user", Password: "pw"},
+			{Host: "127.0.0.3", Port: "1", User: "user", Password: "pw"},
+		},
+		HostStrategy: strategy,
+		MaxSize:      2,
+		StartingSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	return pool
+}
+
+func TestHostOrderFailover(t *testing.T) {
+	pool := newHostPool(t, "")
+	pool.hosts.hosts[0].downUntil = time.Now().Add(time.Minute)
+
+	hosts := pool.hostOrder()
+	want := []string{"127.0.0.2", "127.0.0.3", "127.0.0.1"}
+	for i, h := range hosts {
+		if h.server.Host != want[i] {
+			t.Errorf("have %v, want %v", h.server.Host, want[i])
+		}
+	}
+}
+
+func TestHostOrderRoundRobin(t *testing.T) {
+	pool := newHostPool(t, HOST_STRATEGY_ROUND_ROBIN)
+
+	want := []string{"127.0.0.1", "127.0.0.2", "127.0.0.3", "127.0.0.1"}
+	for _, w := range want {
+		have := pool.hostOrder()[0].server.Host
+		if have != w {
+			t.Errorf("have %v, want %v", have, w)
+		}
+	}
+}
+
+func TestHostOrderLeastBusy(t *testing.T) {
+	pool := newHostPool(t, HOST_STRATEGY_LEAST_BUSY)
+	busy := NewSQLJob("busy")
+	busy.daemon = pool.hosts.hosts[0].server
+	pool.trackBorrow(busy)
+
+	have := pool.hostOrder()[0].server.Host
+	if have != "127.0.0.2" {
+		t.Errorf("have %v, want 127.0.0.2", have)
+	}
+}
+
+// Connect with all hosts unreachable
+func TestConnectHostDown(t *testing.T) {
+	pool := newHostPool(t, HOST_STRATEGY_FAILOVER)
+
+	err := pool.connectHost(NewSQLJob("test"))
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+	for _, h := range pool.hosts.hosts {
+		if !h.downUntil.After(time.Now()) {
+			t.Errorf("host %v should be marked down", h.server.Host)
+		}
+	}
+}
+
+func TestInvalidHostStrategy(t *testing.T) {
+	creds := DaemonServer{Host: "localhost", User: "user", Password: "pw"}
+	_, err := NewPool(PoolOptions{Creds: creds, MaxSize: 1, StartingSize: 1, HostStrategy: "RANDOM"})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
@@ -0,0 +1,164 @@
+package mapepire
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	JSON_LINES = "LINES"
+	JSON_ARRAY = "ARRAY"
+)
+
+// Represents options for the JSON export
+type JSONOptions struct {
+	Format          string // JSON_LINES (default) or JSON_ARRAY
+	IncludeMetadata bool   // Whether to write the metadata of the columns first
+}
+
+// Represents the metadata written by the JSON export
+type jsonMetadata struct {
+	Columns []jsonColumn `json:"columns"`
+}
+
+// Represents a column in the metadata of the JSON export
+type jsonColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Label       string `json:"label"`
+	DisplaySize int    `json:"display_size"`
+}
+
+// Executes the query and writes each row as a JSON object, either one per line
+// (NDJSON) or as a single JSON array. With metadata, the lines start with a
+// {"metadata":...} object, and the array is wrapped in {"metadata":...,"data":[...]}.
+// The rows are fetched page by page and written after each page, so large
+// results can be proxied with bounded memory.
+func ExportJSON(w io.Writer, query *Query, options JSONOptions) error {
+	if options.Format == "" {
+		options.Format = JSON_LINES
+	}
+	if options.Format != JSON_LINES && options.Format != JSON_ARRAY {
+		return fmt.Errorf("invalid JSON format: %v", options.Format)
+	}
+	array := options.Format == JSON_ARRAY
+	buf := bufio.NewWriter(w)
+
+	first, rows := true, 0
+	err := query.Stream(func(page *ServerResponse) error {
+		if page.Metadata == nil {
+			return nil
+		}
+		columns := page.Metadata.Columns
+
+		if first {
+			err := writeJSONStart(buf, columns, options)
+			if err != nil {
+				return err
+			}
+			first = false
+		}
+
+		for _, row := range page.Rows() {
+			if array && rows > 0 {
+				buf.WriteByte(',')
+			}
+			err := writeJSONRow(buf, columns, row)
+			if err != nil {
+				return err
+			}
+			if !array {
+				buf.WriteByte('\n')
+			}
+			rows++
+		}
+		return flushJSON(buf, w)
+	})
+	if err != nil {
+		return err
+	}
+
+	if first {
+		err = writeJSONStart(buf, nil, options)
+		if err != nil {
+			return err
+		}
+	}
+	if array {
+		buf.WriteByte(']')
+		if options.IncludeMetadata {
+			buf.WriteByte('}')
+		}
+		buf.WriteByte('\n')
+	}
+	return flushJSON(buf, w)
+}
+
+// Runs the SQL and writes each row as a JSON object
+func (s *SQLJob) ExportJSON(w io.Writer, sql string, options JSONOptions) error {
+	query, err := s.QueryWithOptions(sql, QueryOptions{Rows: MAX_FETCH_SIZE})
+	if err != nil {
+		return err
+	}
+	return ExportJSON(w, query, options)
+}
+
+// writes the metadata, if any, and the start of the array
+func writeJSONStart(w *bufio.Writer, columns []column, options JSONOptions) error {
+	if options.IncludeMetadata {
+		meta := jsonMetadata{Columns: []jsonColumn{}}
+		for _, c := range columns {
+			meta.Columns = append(meta.Columns, jsonColumn{Name: c.Name, Type: c.Type, Label: c.Label, DisplaySize: c.DisplaySize})
+		}
+		data, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+
+		w.WriteString(`{"metadata":`)
+		w.Write(data)
+		if options.Format == JSON_ARRAY {
+			w.WriteString(`,"data":`)
+		} else {
+			w.WriteString("}\n")
+		}
+	}
+	if options.Format == JSON_ARRAY {
+		w.WriteByte('[')
+	}
+	return nil
+}
+
+// writes a row as JSON object, keeping the order of the columns
+func writeJSONRow(w *bufio.Writer, columns []column, row []any) error {
+	w.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		key, err := json.Marshal(columns[i].Name)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		w.Write(key)
+		w.WriteByte(':')
+		w.Write(data)
+	}
+	return w.WriteByte('}')
+}
+
+// flushes the buffer and the underlying writer, if it can be flushed
+func flushJSON(buf *bufio.Writer, w io.Writer) error {
+	err := buf.Flush()
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return err
+}
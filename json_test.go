@@ -0,0 +1,89 @@
+package mapepire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSONRow(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	columns := []column{{Name: "ID"}, {Name: "NAME"}, {Name: "NOTE"}}
+
+	writeJSONRow(w, columns, []any{1.0, "Max \"M\"", nil})
+	w.Flush()
+
+	want := `{"ID":1,"NAME":"Max \"M\"","NOTE":null}`
+	if buf.String() != want {
+		t.Errorf("have %v, want %v", buf.String(), want)
+	}
+}
+
+func TestWriteJSONStart(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	columns := []column{{Name: "ID", Type: "DECIMAL", Label: "ID", DisplaySize: 10}}
+
+	writeJSONStart(w, columns, JSONOptions{Format: JSON_LINES, IncludeMetadata: true})
+	w.Flush()
+
+	var have struct {
+		Metadata jsonMetadata
+	}
+	err := json.Unmarshal(buf.Bytes(), &have)
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if len(have.Metadata.Columns) != 1 || have.Metadata.Columns[0].Type != "DECIMAL" {
+		t.Errorf("have %v, want column ID of type DECIMAL", have.Metadata)
+	}
+}
+
+// Export a query as JSON array with metadata
+func TestExportJSONArray(t *testing.T) {
+	job, _ := initSQLTable("SELECT * FROM TEMPTEST", QueryOptions{})
+
+	var buf bytes.Buffer
+	err := job.ExportJSON(&buf, "SELECT ID, DESCRIPTION FROM TEMPTEST ORDER BY ID", JSONOptions{Format: JSON_ARRAY, IncludeMetadata: true})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	var have struct {
+		Metadata jsonMetadata
+		Data     []map[string]any
+	}
+	err = json.Unmarshal(buf.Bytes(), &have)
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if len(have.Data) != 5 {
+		t.Errorf("have %v rows, want 5", len(have.Data))
+	}
+}
+
+// Export a query as JSON lines
+func TestExportJSONLines(t *testing.T) {
+	job, _ := initSQLTable("SELECT * FROM TEMPTEST", QueryOptions{})
+
+	var buf bytes.Buffer
+	err := job.ExportJSON(&buf, "SELECT ID, DESCRIPTION FROM TEMPTEST ORDER BY ID", JSONOptions{})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Errorf("have %v lines, want 5", len(lines))
+	}
+}
+
+func TestExportJSONInvalid(t *testing.T) {
+	err := ExportJSON(&bytes.Buffer{}, &Query{}, JSONOptions{Format: "XML"})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
@@ -0,0 +1,89 @@
+package mapepire
+
+import (
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Represents a job that is currently borrowed from the pool
+type BorrowInfo struct {
+	JobID   string    // The unique identifier of the job
+	Jobname string    // The name of the DB Job
+	Since   time.Time // When the job was borrowed
+	Stack   string    // Stack trace of the GetJob call (only recorded with leak detection)
+}
+
+// Tracks the jobs borrowed from the pool
+type borrowList struct {
+	list map[*SQLJob]*borrow // All outstanding borrows
+	lock sync.Mutex          // Mutex
+}
+
+// Represents a single borrow
+type borrow struct {
+	info  BorrowInfo  // Information about the borrow
+	timer *time.Timer // Reports the job as leaked after the threshold
+}
+
+// records a borrowed job and starts the leak detection, if enabled
+func (jp *JobPool) trackBorrow(job *SQLJob) {
+	b := &borrow{info: BorrowInfo{JobID: job.ID, Jobname: job.Jobname, Since: time.Now()}}
+
+	if jp.options.LeakThreshold > 0 {
+		b.info.Stack = string(debug.Stack())
+		threshold := time.Duration(jp.options.LeakThreshold) * time.Second
+		info := b.info
+		b.timer = time.AfterFunc(threshold, func() {
+			jp.reportLeak(info)
+		})
+	}
+
+	jp.borrows.lock.Lock()
+	if jp.borrows.list == nil {
+		jp.borrows.list = make(map[*SQLJob]*borrow)
+	}
+	jp.borrows.list[job] = b
+	jp.borrows.lock.Unlock()
+}
+
+// removes a returned job and stops its leak detection.
+// Reports whether the job has been borrowed from the pool.
+func (jp *JobPool) untrackBorrow(job *SQLJob) bool {
+	jp.borrows.lock.Lock()
+	b, ok := jp.borrows.list[job]
+	delete(jp.borrows.list, job)
+	jp.borrows.lock.Unlock()
+
+	if ok && b.timer != nil {
+		b.timer.Stop()
+	}
+	return ok
+}
+
+// reports a job that has been borrowed longer than the threshold
+func (jp *JobPool) reportLeak(info BorrowInfo) {
+	if jp.options.LeakReporter != nil {
+		jp.options.LeakReporter(info)
+		return
+	}
+	log.Printf("mapepire: job %q (%v) borrowed for more than %v seconds, possible leak at:\n%s",
+		info.JobID, info.Jobname, jp.options.LeakThreshold, info.Stack)
+}
+
+// Receive all jobs that are currently borrowed from the pool, oldest first
+func (jp *JobPool) Borrows() []BorrowInfo {
+	jp.borrows.lock.Lock()
+	defer jp.borrows.lock.Unlock()
+
+	borrows := make([]BorrowInfo, 0, len(jp.borrows.list))
+	for _, b := range jp.borrows.list {
+		borrows = append(borrows, b.info)
+	}
+	sort.Slice(borrows, func(i, j int) bool {
+		return borrows[i].Since.Before(borrows[j].Since)
+	})
+	return borrows
+}
@@ -0,0 +1,68 @@
+package mapepire
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeakDetection(t *testing.T) {
+	leaked := make(chan BorrowInfo, 1)
+	creds := DaemonServer{Host: "localhost", User: "user", Password: "pw"}
+	pool, err := NewPool(PoolOptions{
+		Creds:         creds,
+		MaxSize:       2,
+		StartingSize:  1,
+		LeakThreshold: 1,
+		LeakReporter:  func(info BorrowInfo) { leaked <- info },
+	})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	job := NewSQLJob("leaky")
+	pool.trackBorrow(job)
+
+	borrows := pool.Borrows()
+	if len(borrows) != 1 {
+		t.Fatalf("have %v borrows, want 1", len(borrows))
+	}
+	if borrows[0].JobID != "leaky" || borrows[0].Stack == "" {
+		t.Errorf("have %v, want job 'leaky' with stack trace", borrows[0])
+	}
+
+	select {
+	case info := <-leaked:
+		if info.JobID != "leaky" {
+			t.Errorf("have %v, want 'leaky'", info.JobID)
+		}
+	case <-time.After(3 * time.Second):
+		t.Errorf("should report leaked job")
+	}
+
+	pool.untrackBorrow(job)
+	if len(pool.Borrows()) != 0 {
+		t.Errorf("should not have borrows")
+	}
+}
+
+func TestLeakDetectionReturned(t *testing.T) {
+	leaked := make(chan BorrowInfo, 1)
+	creds := DaemonServer{Host: "localhost", User: "user", Password: "pw"}
+	pool, _ := NewPool(PoolOptions{
+		Creds:         creds,
+		MaxSize:       2,
+		StartingSize:  1,
+		LeakThreshold: 1,
+		LeakReporter:  func(info BorrowInfo) { leaked <- info },
+	})
+
+	job := NewSQLJob("returned")
+	pool.trackBorrow(job)
+	pool.untrackBorrow(job)
+
+	select {
+	case <-leaked:
+		t.Errorf("should not report returned job")
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,134 @@
+package mapepire
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	PRIORITY_BATCH       = -1
+	PRIORITY_NORMAL      = 0
+	PRIORITY_INTERACTIVE = 1
+)
+
+// Limits the rate and concurrency of the borrows of a pool.
+// Waiting borrows with a higher priority are served first.
+type limiter struct {
+	rate      float64     // Executions per second (0 for no limit)
+	burst     float64     // Max number of tokens
+	tokens    float64     // Available tokens
+	last      time.Time   // Last refill of the tokens
+	maxActive int         // Max concurrent executions (0 for no limit)
+	active    int         // Running executions
+	waiters   []*waiter   // Waiting executions, by priority and arrival
+	timer     *time.Timer // Wakes up waiters once a token is available
+	lock      sync.Mutex  // Mutex
+}
+
+// Represents a waiting execution
+type waiter struct {
+	priority int           // Priority of the execution
+	ready    chan struct{} // Closed once the execution may start
+}
+
+// Receive a new limiter, or nil if nothing is limited
+func newLimiter(rate float64, maxActive int) *limiter {
+	if rate <= 0 && maxActive <= 0 {
+		return nil
+	}
+	burst := math.Max(1, math.Ceil(rate))
+	return &limiter{rate: rate, burst: burst, tokens: burst, last: time.Now(), maxActive: maxActive}
+}
+
+// waits until an execution with the priority may start
+func (l *limiter) acquire(ctx context.Context, priority int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.lock.Lock()
+	w := &waiter{priority: priority, ready: make(chan struct{})}
+	i := len(l.waiters)
+	for i > 0 && l.waiters[i-1].priority < priority {
+		i--
+	}
+	l.waiters = append(l.waiters, nil)
+	copy(l.waiters[i+1:], l.waiters[i:])
+	l.waiters[i] = w
+	l.dispatch()
+	l.lock.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		select {
+		case <-w.ready:
+			l.active--
+			l.dispatch()
+		default:
+			l.remove(w)
+		}
+		return ctx.Err()
+	}
+}
+
+// marks an execution as finished
+func (l *limiter) release() {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	l.active--
+	l.dispatch()
+	l.lock.Unlock()
+}
+
+// starts waiting executions in order while possible, the lock must be held
+func (l *limiter) dispatch() {
+	if l.rate > 0 {
+		now := time.Now()
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+	}
+
+	for len(l.waiters) > 0 {
+		if l.maxActive > 0 && l.active >= l.maxActive {
+			return
+		}
+		if l.rate > 0 && l.tokens < 1 {
+			if l.timer == nil {
+				wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+				l.timer = time.AfterFunc(wait, func() {
+					l.lock.Lock()
+					l.timer = nil
+					l.dispatch()
+					l.lock.Unlock()
+				})
+			}
+			return
+		}
+
+		if l.rate > 0 {
+			l.tokens--
+		}
+		l.active++
+		close(l.waiters[0].ready)
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// removes a waiter from the queue, the lock must be held
+func (l *limiter) remove(w *waiter) {
+	for i, waiting := range l.waiters {
+		if waiting == w {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package mapepire
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterPriority(t *testing.T) {
+	l := newLimiter(0, 1)
+	l.acquire(context.Background(), PRIORITY_NORMAL)
+
+	order := make(chan int, 2)
+	go func() {
+		l.acquire(context.Background(), PRIORITY_BATCH)
+		order <- PRIORITY_BATCH
+		l.release()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	go func() {
+		l.acquire(context.Background(), PRIORITY_INTERACTIVE)
+		order <- PRIORITY_INTERACTIVE
+		l.release()
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	l.release()
+	if have := <-order; have != PRIORITY_INTERACTIVE {
+		t.Errorf("have %v, want %v", have, PRIORITY_INTERACTIVE)
+	}
+	if have := <-order; have != PRIORITY_BATCH {
+		t.Errorf("have %v, want %v", have, PRIORITY_BATCH)
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	l := newLimiter(10, 0)
+	start := time.Now()
+	for i := 0; i < 12; i++ {
+		l.acquire(context.Background(), PRIORITY_NORMAL)
+		l.release()
+	}
+
+	have := time.Since(start)
+	if have < 150*time.Millisecond {
+		t.Errorf("have %v, want at least 150ms", have)
+	}
+}
+
+func TestLimiterContext(t *testing.T) {
+	l := newLimiter(0, 1)
+	l.acquire(context.Background(), PRIORITY_NORMAL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := l.acquire(ctx, PRIORITY_NORMAL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("have %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(l.waiters) != 0 {
+		t.Errorf("have %v waiters, want 0", len(l.waiters))
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	if newLimiter(0, 0) != nil {
+		t.Errorf("should not limit")
+	}
+}
+
+func TestLimitedBorrow(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: DaemonServer{Host: "localhost", Port: "1", Password: "pw"}, MaxSize: 1, StartingSize: 1, MaxConcurrent: 1})
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	defer pool.Close()
+
+	pool.limiter.acquire(context.Background(), PRIORITY_NORMAL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = pool.WithJob(ctx, func(job *SQLJob) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("have %v, want the borrow to wait for the limit", err)
+	}
+
+	// a borrow that fails to connect gives its slot back
+	pool.limiter.release()
+	pool.WithJob(context.Background(), func(job *SQLJob) error { return nil })
+	if pool.limiter.active != 0 {
+		t.Errorf("have %v active borrows, want 0", pool.limiter.active)
+	}
+}
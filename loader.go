@@ -0,0 +1,494 @@
+package mapepire
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	LOAD_FORMAT_CSV    = "CSV"
+	LOAD_FORMAT_NDJSON = "NDJSON"
+)
+
+// Matches identifiers that can be used without delimiters
+var ordinaryIdentifier = regexp.MustCompile(`^[A-Z#@$][A-Z0-9_#@$]*$`)
+
+// Represents options for loading rows into a table
+type LoadOptions struct {
+	Schema         string    // Schema of the target table
+	Table          string    // Target table, SQL or system name
+	Format         string    // LOAD_FORMAT_CSV (default) or LOAD_FORMAT_NDJSON
+	Delimiter      rune      // Field delimiter of CSV input, comma if not set
+	NullValue      string    // CSV value that is loaded as NULL
+	BatchSize      int       // The amount of rows sent per request
+	CommitInterval int       // The amount of rows between commits without autocommit (0 to commit at the end)
+	Parallelism    int       // The amount of jobs loading concurrently, at most the max size of the pool
+	RejectWriter   io.Writer // Receives rejected rows as JSON lines, if set
+}
+
+// Represents the result of a load
+type LoadResult struct {
+	Rows     int // The amount of rows read
+	Loaded   int // The amount of rows inserted
+	Rejected int // The amount of rows rejected
+}
+
+// Represents a rejected row written to the reject writer
+type loadReject struct {
+	Line   int    `json:"line"`
+	Error  string `json:"error"`
+	Record any    `json:"record"`
+}
+
+// Represents a row read from the input
+type loadRow struct {
+	line   int   // Line or record number of the input
+	record any   // Original record of the input
+	values []any // Parameters of the row
+	err    error // Why the row is rejected before inserting it, if it is
+}
+
+// Represents a column of the target table
+type loadColumn struct {
+	name       string // SQL name of the column
+	systemName string // System name of the column
+	dataType   string // Data type of the column
+}
+
+// Loads rows from CSV (with header) or NDJSON into a table. The input columns
+// are mapped to the table columns by name, using the catalog of the table.
+// Rows are inserted in batches by concurrent jobs of the pool, rejected rows
+// are written to the reject writer. Without autocommit, each job commits every
+// CommitInterval rows and once it is done; only committed rows count as loaded.
+func (jp *JobPool) Load(ctx context.Context, r io.Reader, options LoadOptions) (*LoadResult, error) {
+	if options.Schema == "" || options.Table == "" {
+		return nil, fmt.Errorf("schema and table required")
+	}
+	if options.Format == "" {
+		options.Format = LOAD_FORMAT_CSV
+	}
+	if options.Format != LOAD_FORMAT_CSV && options.Format != LOAD_FORMAT_NDJSON {
+		return nil, fmt.Errorf("invalid load format: %v", options.Format)
+	}
+	if options.Parallelism <= 0 {
+		options.Parallelism = 1
+	}
+	if options.Parallelism > jp.options.MaxSize {
+		// further jobs would only wait for the pool and time out
+		options.Parallelism = jp.options.MaxSize
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DEFAULT_BATCH_SIZE
+	}
+
+	var tableColumns []loadColumn
+	err := jp.withJob(ctx, PRIORITY_BATCH, func(job *SQLJob) error {
+		var err error
+		tableColumns, err = job.loadColumns(options.Schema, options.Table)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	loader := &loader{pool: jp, options: options, result: &LoadResult{}}
+	batches := make(chan []loadRow, options.Parallelism)
+	errs := make(chan error, options.Parallelism+1)
+
+	columns := make(chan []loadColumn, 1)
+	go func() {
+		defer close(batches)
+		err := loader.read(ctx, r, tableColumns, columns, batches)
+		if err != nil {
+			errs <- err
+			cancel()
+		}
+	}()
+
+	insertColumns, ok := <-columns
+	if !ok {
+		// the input is empty or unreadable
+		for range batches {
+		}
+		select {
+		case err := <-errs:
+			return loader.result, err
+		default:
+			return loader.result, nil
+		}
+	}
+	sql, err := insertSQL(options.Schema, options.Table, insertColumns)
+	if err != nil {
+		cancel()
+		for range batches {
+		}
+		return nil, err
+	}
+
+	wg := new(sync.WaitGroup)
+	wg.Add(options.Parallelism)
+	for i := 0; i < options.Parallelism; i++ {
+		go func() {
+			defer wg.Done()
+			err := loader.insert(ctx, sql, batches)
+			if err != nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var loadErr error
+	for err := range errs {
+		loadErr = errors.Join(loadErr, err)
+	}
+	return loader.result, loadErr
+}
+
+// Loads rows into a table
+type loader struct {
+	pool    *JobPool    // Pool of the jobs inserting the rows
+	options LoadOptions // Options of the load
+	result  *LoadResult // Result of the load
+	lock    sync.Mutex  // Mutex
+}
+
+// reads the input and sends it in batches, after sending the mapped columns
+func (l *loader) read(ctx context.Context, r io.Reader, tableColumns []loadColumn, columns chan<- []loadColumn, batches chan<- []loadRow) error {
+	defer close(columns)
+
+	var next func() (*loadRow, error)
+	var mapped []loadColumn
+
+	if l.options.Format == LOAD_FORMAT_CSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		if l.options.Delimiter != 0 {
+			reader.Comma = l.options.Delimiter
+		}
+		header, err := reader.Read()
+		if err != nil {
+			return fmt.Errorf("error reading CSV header: %v", err)
+		}
+		mapped, err = mapColumns(header, tableColumns)
+		if err != nil {
+			return err
+		}
+
+		next = func() (*loadRow, error) {
+			record, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			line, _ := reader.FieldPos(0)
+			if len(record) != len(mapped) {
+				err := fmt.Errorf("have %v fields, want %v", len(record), len(mapped))
+				return &loadRow{line: line, record: record, err: err}, nil
+			}
+			values := make([]any, len(record))
+			for i, field := range record {
+				if i < len(mapped) && l.isNull(field, mapped[i]) {
+					continue
+				}
+				values[i] = field
+			}
+			return &loadRow{line: line, record: record, values: values}, nil
+		}
+	} else {
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		line := 0
+		var first map[string]any
+		var names []string
+
+		read := func() (map[string]any, error) {
+			var object map[string]any
+			err := decoder.Decode(&object)
+			line++
+			return object, err
+		}
+		first, err := read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error reading JSON: %v", err)
+		}
+		for name := range first {
+			names = append(names, name)
+		}
+		mapped, err = mapColumns(names, tableColumns)
+		if err != nil {
+			return err
+		}
+
+		// all objects need the keys of the first one
+		toRow := func(object map[string]any, line int) *loadRow {
+			values := make([]any, len(names))
+			for i, name := range names {
+				value, ok := object[name]
+				if !ok {
+					return &loadRow{line: line, record: object, err: fmt.Errorf("missing key %v", name)}
+				}
+				values[i] = value
+			}
+			if len(object) != len(names) {
+				for key := range object {
+					if _, ok := first[key]; !ok {
+						return &loadRow{line: line, record: object, err: fmt.Errorf("unexpected key %v", key)}
+					}
+				}
+			}
+			return &loadRow{line: line, record: object, values: values}
+		}
+		pending := toRow(first, line)
+
+		next = func() (*loadRow, error) {
+			if pending != nil {
+				row := pending
+				pending = nil
+				return row, nil
+			}
+			object, err := read()
+			if err != nil {
+				return nil, err
+			}
+			return toRow(object, line), nil
+		}
+	}
+	columns <- mapped
+
+	batch := make([]loadRow, 0, l.options.BatchSize)
+	for {
+		row, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading input: %v", err)
+		}
+		l.count(func(result *LoadResult) { result.Rows++ })
+		if row.err != nil {
+			err := l.reject(*row, row.err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		batch = append(batch, *row)
+		if len(batch) == l.options.BatchSize {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return nil
+			}
+			batch = make([]loadRow, 0, l.options.BatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
+
+// inserts batches with a job of the pool until all batches have been inserted.
+// Without autocommit, rows are only counted as loaded once they are committed,
+// as the job rolls back uncommitted rows when it is added back to the pool.
+func (l *loader) insert(ctx context.Context, sql string, batches <-chan []loadRow) error {
+	return l.pool.withJob(ctx, PRIORITY_BATCH, func(job *SQLJob) error {
+		autoCommit := job.autoCommit()
+		uncommitted, loaded := 0, 0
+		commit := func() error {
+			err := job.EndTransaction(TRANSACTION_COMMIT)
+			if err != nil {
+				return err
+			}
+			l.count(func(r *LoadResult) { r.Loaded += loaded })
+			uncommitted, loaded = 0, 0
+			return nil
+		}
+
+		for batch := range batches {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			rows := make([][]any, len(batch))
+			for i, row := range batch {
+				rows[i] = row.values
+			}
+			result, err := job.ExecuteBatch(sql, rows, BatchOptions{BatchSize: l.options.BatchSize, ContinueOnError: true})
+			if err != nil {
+				return err
+			}
+
+			rejected, err := l.rejectBatch(batch, result.Errors)
+			if err != nil {
+				return err
+			}
+			if autoCommit {
+				l.count(func(r *LoadResult) { r.Loaded += len(batch) - rejected })
+				continue
+			}
+
+			loaded += len(batch) - rejected
+			uncommitted += len(batch)
+			if l.options.CommitInterval > 0 && uncommitted >= l.options.CommitInterval {
+				err := commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if uncommitted > 0 {
+			return commit()
+		}
+		return nil
+	})
+}
+
+// writes a rejected row to the reject writer
+func (l *loader) reject(row loadRow, rowErr error) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.result.Rejected++
+	if l.options.RejectWriter == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(loadReject{Line: row.line, Error: rowErr.Error(), Record: row.record})
+	if err != nil {
+		return err
+	}
+	_, err = l.options.RejectWriter.Write(append(data, '\n'))
+	return err
+}
+
+// rejects the failed rows of a batch and returns their number.
+// If the failing row is unknown, all rows of the batch are rejected.
+func (l *loader) rejectBatch(batch []loadRow, rowErrs []BatchError) (int, error) {
+	rejected := 0
+	for _, rowErr := range rowErrs {
+		rows := batch
+		if rowErr.Row >= 0 {
+			rows = batch[rowErr.Row : rowErr.Row+1]
+		}
+		for _, row := range rows {
+			err := l.reject(row, rowErr.Err)
+			if err != nil {
+				return rejected, err
+			}
+			rejected++
+		}
+	}
+	return rejected, nil
+}
+
+// updates the result of the load
+func (l *loader) count(update func(result *LoadResult)) {
+	l.lock.Lock()
+	update(l.result)
+	l.lock.Unlock()
+}
+
+// reports whether a CSV field is loaded as NULL
+func (l *loader) isNull(field string, column loadColumn) bool {
+	if l.options.NullValue != "" && field == l.options.NullValue {
+		return true
+	}
+	return field == "" && !isCharacterType(column.dataType)
+}
+
+// Receive the columns of a table from the catalog
+func (s *SQLJob) loadColumns(schema string, table string) ([]loadColumn, error) {
+	query, err := s.QueryWithOptions(
+		"SELECT COLUMN_NAME, SYSTEM_COLUMN_NAME, DATA_TYPE FROM QSYS2.SYSCOLUMNS WHERE TABLE_SCHEMA = ? AND (TABLE_NAME = ? OR SYSTEM_TABLE_NAME = ?) ORDER BY ORDINAL_POSITION",
+		QueryOptions{Rows: MAX_FETCH_SIZE, Parameters: [][]any{{strings.ToUpper(schema), strings.ToUpper(table), strings.ToUpper(table)}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []loadColumn
+	err = query.Stream(func(page *ServerResponse) error {
+		for _, row := range page.Data {
+			columns = append(columns, loadColumn{
+				name:       strings.TrimSpace(fmt.Sprint(row["COLUMN_NAME"])),
+				systemName: strings.TrimSpace(fmt.Sprint(row["SYSTEM_COLUMN_NAME"])),
+				dataType:   strings.TrimSpace(fmt.Sprint(row["DATA_TYPE"])),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %v.%v not found", schema, table)
+	}
+	return columns, nil
+}
+
+// maps the input names to the columns of the table
+func mapColumns(names []string, tableColumns []loadColumn) ([]loadColumn, error) {
+	mapped := make([]loadColumn, len(names))
+	for i, name := range names {
+		found := false
+		for _, column := range tableColumns {
+			if strings.EqualFold(name, column.name) || strings.EqualFold(name, column.systemName) {
+				mapped[i] = column
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("column %v not found in table", name)
+		}
+	}
+	return mapped, nil
+}
+
+// builds the INSERT statement for the columns
+func insertSQL(schema string, table string, columns []loadColumn) (string, error) {
+	schema, table = strings.ToUpper(schema), strings.ToUpper(table)
+	names := make([]string, len(columns))
+	markers := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+		markers[i] = "?"
+	}
+
+	for _, identifier := range append([]string{schema, table}, names...) {
+		if !ordinaryIdentifier.MatchString(identifier) {
+			return "", fmt.Errorf("identifier %v requires delimiters, which are not supported", identifier)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s.%s (%s) VALUES (%s)", schema, table, strings.Join(names, ", "), strings.Join(markers, ", ")), nil
+}
+
+// reports whether a data type holds character data
+func isCharacterType(dataType string) bool {
+	switch strings.ToUpper(dataType) {
+	case "CHAR", "VARCHAR", "CLOB", "GRAPHIC", "VARGRAPHIC", "DBCLOB", "NCHAR", "NVARCHAR", "NCLOB":
+		return true
+	}
+	return false
+}
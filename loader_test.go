@@ -0,0 +1,148 @@
+package mapepire
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMapColumns(t *testing.T) {
+	table := []loadColumn{
+		{name: "EMPLOYEE_NUMBER", systemName: "EMPNO", dataType: "CHAR"},
+		{name: "SALARY", systemName: "SALARY", dataType: "DECIMAL"},
+	}
+
+	mapped, err := mapColumns([]string{"salary", "empno"}, table)
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	if mapped[0].name != "SALARY" || mapped[1].name != "EMPLOYEE_NUMBER" {
+		t.Errorf("have %v, want SALARY, EMPLOYEE_NUMBER", mapped)
+	}
+
+	_, err = mapColumns([]string{"bonus"}, table)
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+func TestInsertSQL(t *testing.T) {
+	columns := []loadColumn{{name: "EMPNO"}, {name: "SALARY"}}
+	have, err := insertSQL("sample", "employee", columns)
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	want := "INSERT INTO SAMPLE.EMPLOYEE (EMPNO, SALARY) VALUES (?, ?)"
+	if have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	_, err = insertSQL("SAMPLE", "EMPLOYEE", []loadColumn{{name: "Mixed Case"}})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+func TestLoaderIsNull(t *testing.T) {
+	l := &loader{options: LoadOptions{NullValue: "NULL"}}
+	if !l.isNull("NULL", loadColumn{dataType: "VARCHAR"}) {
+		t.Errorf("NULL should be loaded as NULL")
+	}
+	if l.isNull("", loadColumn{dataType: "VARCHAR"}) {
+		t.Errorf("empty character field should not be loaded as NULL")
+	}
+	if !l.isNull("", loadColumn{dataType: "INTEGER"}) {
+		t.Errorf("empty numeric field should be loaded as NULL")
+	}
+}
+
+// Reject rows that do not match the columns of the input
+func TestLoaderRead(t *testing.T) {
+	table := []loadColumn{{name: "ID", dataType: "INTEGER"}, {name: "NAME", dataType: "VARCHAR"}}
+	inputs := map[string]string{
+		LOAD_FORMAT_CSV:    "ID,NAME\n1,Max\n2\n3,Tom,extra\n4,Olly\n",
+		LOAD_FORMAT_NDJSON: `{"ID":1,"NAME":"Max"}` + "\n" + `{"ID":2}` + "\n" + `{"ID":3,"NAME":"Tom","AGE":30}` + "\n" + `{"ID":4,"NAME":"Olly"}` + "\n",
+	}
+	for format, input := range inputs {
+		var rejects strings.Builder
+		l := &loader{options: LoadOptions{Format: format, BatchSize: 10, RejectWriter: &rejects}, result: &LoadResult{}}
+		columns := make(chan []loadColumn, 1)
+		batches := make(chan []loadRow, 1)
+
+		err := l.read(context.Background(), strings.NewReader(input), table, columns, batches)
+		if err != nil {
+			t.Fatalf("should not throw error: %v", err)
+		}
+		batch := <-batches
+		if len(batch) != 2 || l.result.Rows != 4 || l.result.Rejected != 2 {
+			t.Errorf("%v: have %v rows in the batch and %+v, want 2 rows and 2 rejected", format, len(batch), l.result)
+		}
+		if strings.Count(rejects.String(), "\n") != 2 {
+			t.Errorf("%v: have rejects %v", format, rejects.String())
+		}
+	}
+}
+
+// Reject all rows of a batch whose failing row is unknown
+func TestLoaderRejectBatch(t *testing.T) {
+	batch := []loadRow{{line: 2}, {line: 3}, {line: 4}}
+	rowErr := errors.New("failed")
+	tests := []struct {
+		errors []BatchError
+		want   int
+	}{
+		{[]BatchError{{Row: 1, Err: rowErr}}, 1},
+		{[]BatchError{{Row: -1, Err: rowErr}}, 3},
+		{nil, 0},
+	}
+	for _, test := range tests {
+		l := &loader{result: &LoadResult{}}
+		rejected, err := l.rejectBatch(batch, test.errors)
+		if err != nil {
+			t.Fatalf("should not throw error: %v", err)
+		}
+		if rejected != test.want || l.result.Rejected != test.want {
+			t.Errorf("have %v rejected, want %v", rejected, test.want)
+		}
+	}
+}
+
+// Load with invalid options
+func TestLoadInvalid(t *testing.T) {
+	pool := &JobPool{}
+	_, err := pool.Load(context.Background(), strings.NewReader(""), LoadOptions{Table: "EMPLOYEE"})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+	_, err = pool.Load(context.Background(), strings.NewReader(""), LoadOptions{Schema: "SAMPLE", Table: "EMPLOYEE", Format: "XML"})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// Load CSV into a table
+func TestLoadCSV(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxSize: 2, StartingSize: 2})
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	defer pool.Close()
+
+	input := "DEPTNO,DEPTNAME,ADMRDEPT\nX01,LOADED ONE,A00\nX02,LOADED TWO,A00\n"
+	var rejects strings.Builder
+	result, err := pool.Load(context.Background(), strings.NewReader(input), LoadOptions{
+		Schema:         "SAMPLE",
+		Table:          "DEPARTMENT",
+		CommitInterval: 1,
+		Parallelism:    2,
+		RejectWriter:   &rejects,
+	})
+	if err != nil {
+		t.Fatalf("should not throw error: %v", err)
+	}
+	if result.Rows != 2 || result.Loaded != 2 {
+		t.Errorf("have %+v, want 2 loaded rows", result)
+	}
+	pool.ExecuteSQL("DELETE FROM SAMPLE.DEPARTMENT WHERE DEPTNO IN ('X01', 'X02')")
+}
@@ -0,0 +1,47 @@
+package mapepire
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Represents a single metric in the Prometheus text exposition format
+type metric struct {
+	name  string
+	help  string
+	kind  string
+	value any
+}
+
+// Receive an http.Handler that exposes the pool statistics
+// in the Prometheus text exposition format
+func (jp *JobPool) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		jp.writeMetrics(w)
+	})
+}
+
+// writes the pool statistics in the Prometheus text exposition format
+func (jp *JobPool) writeMetrics(w io.Writer) error {
+	stats := jp.Stats()
+	metrics := []metric{
+		{"mapepire_pool_jobs_idle", "Number of idle jobs in the pool.", "gauge", stats.Idle},
+		{"mapepire_pool_jobs_in_use", "Number of jobs currently borrowed from the pool.", "gauge", stats.InUse},
+		{"mapepire_pool_jobs_created_total", "Number of jobs initialized by the pool.", "counter", stats.TotalCreated},
+		{"mapepire_pool_jobs_closed_total", "Number of jobs closed by the pool.", "counter", stats.Closed},
+		{"mapepire_pool_wait_count_total", "Number of borrows that had to wait for a job.", "counter", stats.WaitCount},
+		{"mapepire_pool_wait_duration_seconds_total", "Total time spent waiting for a job.", "counter", stats.WaitDuration.Seconds()},
+		{"mapepire_pool_health_check_failures_total", "Number of jobs found with a broken connection.", "counter", stats.HealthCheckFailures},
+		{"mapepire_pool_reconnects_total", "Number of jobs that have been reconnected.", "counter", stats.Reconnects},
+	}
+
+	for _, m := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
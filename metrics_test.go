@@ -0,0 +1,34 @@
+package mapepire
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	creds := DaemonServer{Host: "localhost", User: "user", Password: "pw"}
+	pool, err := NewPool(PoolOptions{Creds: creds, MaxSize: 5, StartingSize: 3})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	recorder := httptest.NewRecorder()
+	pool.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	have := recorder.Body.String()
+	want := []string{
+		"# TYPE mapepire_pool_jobs_idle gauge\nmapepire_pool_jobs_idle 3\n",
+		"# TYPE mapepire_pool_jobs_in_use gauge\nmapepire_pool_jobs_in_use 0\n",
+		"# TYPE mapepire_pool_jobs_created_total counter\nmapepire_pool_jobs_created_total 3\n",
+		"mapepire_pool_wait_duration_seconds_total 0\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(have, w) {
+			t.Errorf("have %v, want to contain %v", have, w)
+		}
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("wrong content type %v", recorder.Header().Get("Content-Type"))
+	}
+}
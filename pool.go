@@ -1,6 +1,7 @@
 package mapepire
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -11,17 +12,62 @@ import (
 
 // Connection pool
 type JobPool struct {
-	jobPool chan *SQLJob   // A channel of SQLJobs managed by the pool
-	options PoolOptions    // Represents the options for configuring a connection pool
-	counter *atomic.Uint32 // Atomic counter
+	jobPool chan *SQLJob           // A channel of SQLJobs managed by the pool
+	options PoolOptions            // Represents the options for configuring a connection pool
+	counter *atomic.Uint32         // Atomic counter
+	stats   poolStats              // Usage statistics of the pool
+	closing bool                   // Whether the pool stopped handing out jobs
+	lock    sync.Mutex             // Mutex
+	closed  sync.Once              // Closes the job channel once
+	release chan struct{}          // Signals that a borrowed job has been returned
+	borrows borrowList             // Jobs currently borrowed from the pool
+	hosts   *hostList              // Hosts the jobs connect to
+	breaker *circuitBreaker        // Circuit breaker around connects and query executions
+	limiter *limiter               // Limits the rate and concurrency of borrows
+	reserve func() (func(), error) // Reserves a connection slot of the pool group before connecting, returns its release
+}
+
+// Represents the usage statistics of a connection pool
+type PoolStats struct {
+	Idle                int           // The number of idle jobs in the pool
+	InUse               int           // The number of jobs currently borrowed from the pool
+	TotalCreated        int           // The number of jobs that have been initialized by the pool
+	Closed              int           // The number of jobs closed by the pool
+	WaitCount           int64         // The total number of borrows that had to wait for a job
+	WaitDuration        time.Duration // The total time spent waiting for a job
+	HealthCheckFailures int64         // The number of jobs found with a broken connection
+	Reconnects          int64         // The number of jobs that have been reconnected
+}
+
+// Counters behind PoolStats
+type poolStats struct {
+	inUse               atomic.Int32
+	connections         atomic.Int32
+	closed              atomic.Uint32
+	waitCount           atomic.Int64
+	waitDuration        atomic.Int64
+	healthCheckFailures atomic.Int64
+	reconnects          atomic.Int64
 }
 
 // Represents the options for configuring a connection pool
 type PoolOptions struct {
-	Creds        DaemonServer // Credentials to connect to the server
-	MaxWaitTime  int          // Max time to wait for a job (in seconds)
-	MaxSize      int          // Pool max size
-	StartingSize int          // Pool starting count
+	Creds                 DaemonServer        // Credentials to connect to the server
+	MaxWaitTime           int                 // Max time to wait for a job (in seconds)
+	MaxSize               int                 // Pool max size
+	StartingSize          int                 // Pool starting count
+	LeakThreshold         int                 // Max time a job can be borrowed before it is reported as leaked (in seconds, 0 disables)
+	LeakReporter          func(BorrowInfo)    // Called with leaked jobs, logs them if not set
+	ResetSession          bool                // Whether to reset the session state of a job when it is added back
+	ResetHook             func(*SQLJob) error // Custom reset routine, runs after the built-in one
+	RecycleOnResetFailure bool                // Whether to reconnect a job if its reset fails
+	Hosts                 []DaemonServer      // Hosts to connect to, in order of preference (Creds is used if empty)
+	HostStrategy          string              // FAILOVER (default), ROUND_ROBIN or LEAST_BUSY
+	HostRetryTime         int                 // Time a host is marked down after failing to connect (in seconds)
+	BreakerThreshold      int                 // Consecutive connection failures before the circuit breaker opens (0 disables)
+	BreakerCooldown       int                 // Time the circuit breaker stays open before probing (in seconds)
+	MaxQueriesPerSecond   float64             // Max borrows of jobs per second (0 for no limit)
+	MaxConcurrent         int                 // Max concurrently borrowed jobs (0 for no limit)
 }
 
 // Create a new pool object
@@ -34,8 +80,20 @@ func NewPool(options PoolOptions) (*JobPool, error) {
 		return nil, fmt.Errorf("max size must be greater than or equal to starting size")
 	}
 
-	if options.Creds.Host == "" || options.Creds.Password == "" {
-		return nil, fmt.Errorf("hostname and password required")
+	if len(options.Hosts) == 0 {
+		options.Hosts = []DaemonServer{options.Creds}
+	}
+	for _, host := range options.Hosts {
+		if host.Host == "" || host.Password == "" {
+			return nil, fmt.Errorf("hostname and password required")
+		}
+	}
+	switch options.HostStrategy {
+	case "":
+		options.HostStrategy = HOST_STRATEGY_FAILOVER
+	case HOST_STRATEGY_FAILOVER, HOST_STRATEGY_ROUND_ROBIN, HOST_STRATEGY_LEAST_BUSY:
+	default:
+		return nil, fmt.Errorf("invalid host strategy: %v", options.HostStrategy)
 	}
 
 	jobChannel := make(chan *SQLJob, options.MaxSize)
@@ -56,87 +114,311 @@ func NewPool(options PoolOptions) (*JobPool, error) {
 		jobPool: jobChannel,
 		counter: &counter,
 		options: options,
+		release: make(chan struct{}, 1),
+		hosts:   newHostList(options.Hosts),
+		breaker: newCircuitBreaker(options.BreakerThreshold, options.BreakerCooldown),
+		limiter: newLimiter(options.MaxQueriesPerSecond, options.MaxConcurrent),
 	}
 	return pool, nil
 }
 
 // Receive a job from the pool
-func (jp *JobPool) GetJob() (s *SQLJob, err error) {
+func (jp *JobPool) GetJob() (*SQLJob, error) {
+	return jp.getJob(context.Background(), PRIORITY_NORMAL)
+}
+
+// Receive a job from the pool, waiting at most until the context expires.
+// The borrow waits for the rate and concurrency limits of the pool first,
+// it is released once the job is added back.
+func (jp *JobPool) getJob(ctx context.Context, priority int) (s *SQLJob, err error) {
+	if jp.isClosing() {
+		return nil, fmt.Errorf("pool is shut down")
+	}
+	err = jp.limiter.acquire(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			jp.limiter.release()
+		}
+	}()
+
+	probe, err := jp.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
 	select {
-	case s := <-jp.jobPool:
-		if s.connection == nil {
-			err := s.Connect(jp.options.Creds)
-			if err != nil {
-				return nil, err
+	case s = <-jp.jobPool:
+		if s == nil {
+			err = fmt.Errorf("pool is shut down")
+		}
+	default:
+		start := time.Now()
+		jp.stats.waitCount.Add(1)
+		s, err = jp.waitForJob(ctx)
+		jp.stats.waitDuration.Add(int64(time.Since(start)))
+	}
+	if err != nil {
+		if probe {
+			jp.breaker.releaseProbe()
+		}
+		return nil, err
+	}
+
+	if s.connection == nil {
+		release, err := jp.reserveConnection()
+		if err != nil {
+			if probe {
+				jp.breaker.releaseProbe()
 			}
+			jp.putBack(s)
+			return nil, err
+		}
+		err = jp.connectJob(s)
+		release()
+		jp.breaker.record(err != nil)
+		if err != nil {
+			jp.putBack(s)
+			return nil, err
+		}
+	} else if probe {
+		err := jp.verifyJob(s)
+		jp.breaker.record(err != nil)
+		if err != nil {
+			jp.stats.healthCheckFailures.Add(1)
+			jp.discardConnection(s)
+			jp.putBack(s)
+			return nil, err
+		}
+	}
+	jp.stats.inUse.Add(1)
+	jp.trackBorrow(s)
+	return s, nil
+}
+
+// waits for a job to be added back, or creates a new one after MaxWaitTime
+func (jp *JobPool) waitForJob(ctx context.Context) (*SQLJob, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case s, ok := <-jp.jobPool:
+		if !ok {
+			return nil, fmt.Errorf("pool is shut down")
 		}
 		return s, nil
 	case <-time.After(time.Duration(jp.options.MaxWaitTime) * time.Second):
+		if jp.isClosing() {
+			return nil, fmt.Errorf("pool is shut down")
+		}
 		if jp.GetJobCount() < jp.options.MaxSize {
 			return jp.newPoolJob()
 		}
-		return nil, fmt.Errorf("exceeded time limit")
+		return nil, &PoolExhaustedError{Method: "waitForJob()", Message: "exceeded time limit"}
 	}
 }
 
+// creates a new job, it is connected by getJob
 func (jp *JobPool) newPoolJob() (*SQLJob, error) {
 	jobCount := fmt.Sprint(jp.counter.Add(1))
 
 	id := "PoolJob " + jobCount
-	job := NewSQLJob(id)
+	return NewSQLJob(id), nil
+}
+
+// reserves a slot for another connection, if the pool belongs to a pool group.
+// The slot is released once the connection is counted as open or has failed.
+func (jp *JobPool) reserveConnection() (func(), error) {
+	if jp.reserve == nil {
+		return func() {}, nil
+	}
+	return jp.reserve()
+}
 
-	err := job.Connect(jp.options.Creds)
+// connects a job and takes a snapshot of its session, if it gets reset
+func (jp *JobPool) connectJob(s *SQLJob) error {
+	reconnect := s.Jobname != ""
+	err := jp.connectHost(s)
 	if err != nil {
-		return nil, err
+		if reconnect {
+			jp.stats.healthCheckFailures.Add(1)
+		}
+		return err
 	}
-	return job, nil
+	jp.stats.connections.Add(1)
+	if reconnect {
+		jp.stats.reconnects.Add(1)
+	}
+
+	if jp.options.ResetSession {
+		s.session, err = s.getSessionState()
+		if err != nil {
+			jp.discardConnection(s)
+			return err
+		}
+	}
+	return nil
 }
 
-// Add a job back to the pool
+// Add a job back to the pool.
+// The session of the job is reset first, if configured.
+// Once the pool is shut down, the job is closed instead.
 func (jp *JobPool) AddJob(s *SQLJob) error {
+	return jp.addJob(s, false)
+}
+
+// adds a job back to the pool, cleaned tells whether its cursors and
+// transactions have already been cleaned up
+func (jp *JobPool) addJob(s *SQLJob, cleaned bool) error {
 	if jp.jobPool == nil {
 		return fmt.Errorf("pool does not exist")
 	}
+
+	var resetErr error
+	if !jp.isClosing() && s.connection != nil {
+		resetErr = jp.resetJob(s, cleaned)
+	}
+
+	jp.lock.Lock()
+	if jp.closing {
+		borrowed := jp.untrackBorrow(s)
+		jp.lock.Unlock()
+
+		// the job is closed without holding the lock, as it needs round trips
+		err := jp.closeJob(s)
+		if borrowed {
+			jp.stats.inUse.Add(-1)
+			jp.limiter.release()
+		}
+		jp.notifyRelease()
+		return err
+	}
+	defer jp.lock.Unlock()
+
 	if len(jp.jobPool) >= jp.options.MaxSize {
 		return fmt.Errorf("not enough space in the pool")
 	}
+	if jp.untrackBorrow(s) {
+		jp.stats.inUse.Add(-1)
+		jp.limiter.release()
+	}
 	jp.jobPool <- s
-	return nil
+	return resetErr
 }
 
-// Execute a SQL query with a job from the pool
-func (jp *JobPool) ExecuteSQL(sql string) (*ServerResponse, error) {
-	return jp.ExecuteSQLWithOptions(sql, QueryOptions{})
+// resets the session of a job with the built-in routine and the reset hook.
+// If the reset fails, the job is recycled if configured.
+func (jp *JobPool) resetJob(s *SQLJob, cleaned bool) error {
+	var err error
+	if jp.options.ResetSession {
+		if !cleaned {
+			err = s.cleanup()
+		}
+		if err == nil {
+			err = s.resetSession()
+		}
+	}
+	if err == nil && jp.options.ResetHook != nil {
+		err = jp.options.ResetHook(s)
+	}
+
+	if err != nil && jp.options.RecycleOnResetFailure {
+		jp.discardConnection(s)
+		return nil
+	}
+	return err
 }
 
-// Execute a SQL query with options, using a job from the pool
-func (jp *JobPool) ExecuteSQLWithOptions(command string, queryops QueryOptions) (*ServerResponse, error) {
+// puts an unconnected job back without counting it as borrowed,
+// so the pool does not lose capacity when a connect fails
+func (jp *JobPool) putBack(s *SQLJob) {
+	jp.lock.Lock()
+	defer jp.lock.Unlock()
 
-	job, err := jp.GetJob()
-	if err != nil {
-		return nil, err
+	if !jp.closing && len(jp.jobPool) < jp.options.MaxSize {
+		jp.jobPool <- s
 	}
+}
+
+// Borrow a job from the pool and run fn with it. The job is always added back
+// to the pool afterwards: open cursors are closed and pending transactions are
+// rolled back. If the connection of the job broke, it is discarded and the job
+// reconnects on the next borrow.
+func (jp *JobPool) WithJob(ctx context.Context, fn func(job *SQLJob) error) error {
+	return jp.withJob(ctx, PRIORITY_NORMAL, fn)
+}
 
-	query, err := job.QueryWithOptions(command, queryops)
+// borrows a job with the priority for the limits of the pool and runs fn with it
+func (jp *JobPool) withJob(ctx context.Context, priority int, fn func(job *SQLJob) error) (err error) {
+	job, err := jp.getJob(ctx, priority)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	resp, executeErr := query.Execute()
+	defer func() {
+		if r := recover(); r != nil {
+			jp.discardConnection(job)
+			jp.AddJob(job)
+			panic(r)
+		}
+		err = errors.Join(err, jp.releaseJob(job, err))
+	}()
 
+	return fn(job)
+}
+
+// cleans up a job and adds it back to the pool
+func (jp *JobPool) releaseJob(job *SQLJob, jobErr error) error {
 	var wsErr *WebsocketError
-	if errors.As(executeErr, &wsErr) {
+	failed := errors.As(jobErr, &wsErr) || job.connection == nil
+	jp.breaker.record(failed)
+
+	if failed {
+		jp.stats.healthCheckFailures.Add(1)
+		jp.discardConnection(job)
+	} else if err := job.cleanup(); err != nil {
+		jp.discardConnection(job)
+	}
+	return jp.addJob(job, true)
+}
+
+// closes the connection of a job, it reconnects on the next borrow
+func (jp *JobPool) discardConnection(job *SQLJob) {
+	if job.connection != nil {
+		jp.stats.connections.Add(-1)
 		job.connection.Close()
 		job.connection = nil
 	}
+	job.queryList = newQueryList()
+}
 
-	err = jp.AddJob(job)
-	err = errors.Join(executeErr, err)
-	if err != nil {
-		return resp, err
+// signals a waiting Shutdown that a job has been returned
+func (jp *JobPool) notifyRelease() {
+	select {
+	case jp.release <- struct{}{}:
+	default:
 	}
+}
+
+// Execute a SQL query with a job from the pool
+func (jp *JobPool) ExecuteSQL(sql string) (*ServerResponse, error) {
+	return jp.ExecuteSQLWithOptions(sql, QueryOptions{})
+}
 
-	return resp, nil
+// Execute a SQL query with options, using a job from the pool.
+// The borrow waits for the limits of the pool with the priority of the query.
+func (jp *JobPool) ExecuteSQLWithOptions(command string, queryops QueryOptions) (*ServerResponse, error) {
+	var resp *ServerResponse
+	err := jp.withJob(context.Background(), queryops.Priority, func(job *SQLJob) error {
+		query, err := job.QueryWithOptions(command, queryops)
+		if err != nil {
+			return err
+		}
+		resp, err = query.Execute()
+		return err
+	})
+	return resp, err
 }
 
 // Receive the count of jobs that have been initialized by the pool
@@ -144,16 +426,101 @@ func (jp *JobPool) GetJobCount() int {
 	return int(jp.counter.Load())
 }
 
-// Closes the pool and its jobs
+// receives the number of jobs with an open connection
+func (jp *JobPool) openConnections() int {
+	return int(jp.stats.connections.Load())
+}
+
+// Receive the usage statistics of the pool
+func (jp *JobPool) Stats() PoolStats {
+	return PoolStats{
+		Idle:                len(jp.jobPool),
+		InUse:               int(jp.stats.inUse.Load()),
+		TotalCreated:        jp.GetJobCount(),
+		Closed:              int(jp.stats.closed.Load()),
+		WaitCount:           jp.stats.waitCount.Load(),
+		WaitDuration:        time.Duration(jp.stats.waitDuration.Load()),
+		HealthCheckFailures: jp.stats.healthCheckFailures.Load(),
+		Reconnects:          jp.stats.reconnects.Load(),
+	}
+}
+
+// Closes the pool and its idle jobs.
+// Jobs that are returned afterwards are closed by AddJob.
 func (jp *JobPool) Close() {
+	jp.stopHandingOut()
+	jp.drain()
+	jp.closeChannel()
+}
+
+// Gracefully shuts down the pool. It stops handing out jobs and waits for
+// all borrowed jobs to be returned, closing each job's cursors and connection.
+// If the context expires first, its error is returned and the remaining jobs
+// are closed as soon as they are added back.
+func (jp *JobPool) Shutdown(ctx context.Context) error {
+	jp.stopHandingOut()
+
 	for {
+		jp.drain()
+		if jp.stats.inUse.Load() <= 0 {
+			jp.closeChannel()
+			return nil
+		}
+
 		select {
-		case job := <-jp.jobPool:
-			job.Close()
-			continue
+		case <-jp.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// marks the pool as closing
+func (jp *JobPool) stopHandingOut() {
+	jp.lock.Lock()
+	jp.closing = true
+	jp.lock.Unlock()
+}
+
+// reports whether the pool is closing
+func (jp *JobPool) isClosing() bool {
+	jp.lock.Lock()
+	defer jp.lock.Unlock()
+	return jp.closing
+}
+
+// closes all idle jobs
+func (jp *JobPool) drain() {
+	for {
+		select {
+		case job, ok := <-jp.jobPool:
+			if !ok {
+				return
+			}
+			jp.closeJob(job)
 		default:
-			close(jp.jobPool)
 			return
 		}
 	}
 }
+
+// closes the job channel
+func (jp *JobPool) closeChannel() {
+	jp.lock.Lock()
+	defer jp.lock.Unlock()
+	jp.closed.Do(func() {
+		close(jp.jobPool)
+	})
+}
+
+// closes the open cursors and the connection of a job
+func (jp *JobPool) closeJob(job *SQLJob) error {
+	jp.stats.closed.Add(1)
+	if job.connection == nil {
+		return nil
+	}
+	jp.stats.connections.Add(-1)
+
+	err := job.queryList.closeAll()
+	return errors.Join(err, job.Close())
+}
@@ -1,8 +1,11 @@
 package mapepire
 
 import (
+	"context"
+	"errors"
 	"log"
 	"testing"
+	"time"
 )
 
 func initPoolSQLTable(pool *JobPool) error {
@@ -72,3 +75,133 @@ func TestExecuteSQL(t *testing.T) {
 	log.Println(resp)
 	pool.Close()
 }
+
+func TestStats(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxWaitTime: 1, MaxSize: 3, StartingSize: 2})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	have := pool.Stats()
+	if have.Idle != 2 {
+		t.Errorf("have %v, want 2", have.Idle)
+	}
+	if have.TotalCreated != 2 {
+		t.Errorf("have %v, want 2", have.TotalCreated)
+	}
+
+	job, err := pool.GetJob()
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	have = pool.Stats()
+	if have.Idle != 1 || have.InUse != 1 {
+		t.Errorf("have %v idle and %v in use, want 1 and 1", have.Idle, have.InUse)
+	}
+
+	pool.AddJob(job)
+	have = pool.Stats()
+	if have.Idle != 2 || have.InUse != 0 {
+		t.Errorf("have %v idle and %v in use, want 2 and 0", have.Idle, have.InUse)
+	}
+	pool.Close()
+}
+
+func TestShutdown(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxWaitTime: 1, MaxSize: 2, StartingSize: 1})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	job, err := pool.GetJob()
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = pool.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("have %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	_, err = pool.GetJob()
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+
+	err = pool.AddJob(job)
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if job.GetStatus() != JOBSTATUS_ENDED {
+		t.Errorf("have %v, want %v", job.GetStatus(), JOBSTATUS_ENDED)
+	}
+
+	err = pool.Shutdown(context.Background())
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+}
+
+// Add a job after the pool has been closed
+func TestAddJobAfterClose(t *testing.T) {
+	creds := DaemonServer{Host: "localhost", User: "user", Password: "pw"}
+	pool, err := NewPool(PoolOptions{Creds: creds, MaxSize: 2, StartingSize: 1})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	pool.Close()
+	pool.Close()
+
+	err = pool.AddJob(NewSQLJob("test"))
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if inUse := pool.Stats().InUse; inUse != 0 {
+		t.Errorf("have %v jobs in use, want 0", inUse)
+	}
+	_, err = pool.GetJob()
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+func TestWithJob(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxWaitTime: 1, MaxSize: 1, StartingSize: 1})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	err = initPoolSQLTable(pool)
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	var borrowed *SQLJob
+	err = pool.WithJob(context.Background(), func(job *SQLJob) error {
+		borrowed = job
+		query, err := job.QueryWithOptions("SELECT * FROM TEMPTEST", QueryOptions{Rows: 1})
+		if err != nil {
+			return err
+		}
+		_, err = query.Execute()
+		return err
+	})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	if len(borrowed.queryList.list) != 0 {
+		t.Errorf("have %v open queries, want 0", len(borrowed.queryList.list))
+	}
+	if pool.Stats().InUse != 0 {
+		t.Errorf("have %v jobs in use, want 0", pool.Stats().InUse)
+	}
+
+	want := errors.New("callback failed")
+	have := pool.WithJob(context.Background(), func(job *SQLJob) error {
+		return want
+	})
+	if !errors.Is(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	pool.Close()
+}
@@ -0,0 +1,217 @@
+package mapepire
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Represents the options for configuring a pool group
+type PoolGroupOptions struct {
+	Template       PoolOptions // Options for every sub-pool, Creds and Hosts are set per credential set
+	MaxConnections int         // Max number of open connections across all sub-pools (0 for no limit)
+}
+
+// A group of pools, one per credential set.
+// Sub-pools are created lazily and idle sub-pools are evicted in LRU order
+// once their open connections together reach the connection limit.
+// A sub-pool reserves a slot before it opens a connection, so the limit
+// holds for concurrent borrows; without a free slot the borrow fails.
+type PoolGroup struct {
+	options  PoolGroupOptions               // Options of the pool group
+	pools    map[DaemonServer]*list.Element // All sub-pools by credential set
+	lru      *list.List                     // Sub-pools, most recently used first
+	reserved int                            // Slots reserved for connections being opened
+	lock     sync.Mutex                     // Mutex
+}
+
+// Represents a sub-pool of the group
+type groupEntry struct {
+	creds  DaemonServer // Credentials of the sub-pool
+	pool   *JobPool     // The sub-pool
+	active int          // The number of running WithJob calls
+}
+
+// Create a new pool group
+func NewPoolGroup(options PoolGroupOptions) (*PoolGroup, error) {
+	template := options.Template
+	if template.MaxSize <= 0 {
+		return nil, fmt.Errorf("max size must be greater than 0")
+	} else if template.StartingSize <= 0 {
+		return nil, fmt.Errorf("starting size must be greater than 0")
+	} else if template.MaxSize < template.StartingSize {
+		return nil, fmt.Errorf("max size must be greater than or equal to starting size")
+	}
+	if options.MaxConnections < 0 {
+		return nil, fmt.Errorf("max connections must not be negative")
+	} else if options.MaxConnections > 0 && options.MaxConnections < template.MaxSize {
+		return nil, fmt.Errorf("max connections must be greater than or equal to max size")
+	}
+
+	return &PoolGroup{
+		options: options,
+		pools:   make(map[DaemonServer]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// Receive the pool for the credentials, creating it if needed.
+// The pool may be evicted once it is idle, prefer WithJob to keep it in use.
+func (pg *PoolGroup) Get(creds DaemonServer) (*JobPool, error) {
+	pg.lock.Lock()
+	entry, evicted, err := pg.getUnsafe(creds)
+	pg.lock.Unlock()
+
+	closePools(evicted)
+	if err != nil {
+		return nil, err
+	}
+	return entry.pool, nil
+}
+
+// Borrow a job from the pool for the credentials and run fn with it
+func (pg *PoolGroup) WithJob(ctx context.Context, creds DaemonServer, fn func(job *SQLJob) error) error {
+	pg.lock.Lock()
+	entry, evicted, err := pg.getUnsafe(creds)
+	if err == nil {
+		entry.active++
+	}
+	pg.lock.Unlock()
+
+	closePools(evicted)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		pg.lock.Lock()
+		entry.active--
+		pg.lock.Unlock()
+	}()
+
+	return entry.pool.WithJob(ctx, fn)
+}
+
+// Execute a SQL query with options, using a job from the pool for the credentials
+func (pg *PoolGroup) ExecuteSQLWithOptions(creds DaemonServer, command string, queryops QueryOptions) (*ServerResponse, error) {
+	var resp *ServerResponse
+	err := pg.WithJob(context.Background(), creds, func(job *SQLJob) error {
+		query, err := job.QueryWithOptions(command, queryops)
+		if err != nil {
+			return err
+		}
+		resp, err = query.Execute()
+		return err
+	})
+	return resp, err
+}
+
+// Receive the number of sub-pools
+func (pg *PoolGroup) Len() int {
+	pg.lock.Lock()
+	defer pg.lock.Unlock()
+	return pg.lru.Len()
+}
+
+// Closes all sub-pools
+func (pg *PoolGroup) Close() {
+	pg.lock.Lock()
+	var pools []*JobPool
+	for elem := pg.lru.Front(); elem != nil; elem = elem.Next() {
+		pools = append(pools, elem.Value.(*groupEntry).pool)
+	}
+	pg.pools = make(map[DaemonServer]*list.Element)
+	pg.lru.Init()
+	pg.lock.Unlock()
+
+	closePools(pools)
+}
+
+// receives or creates the sub-pool for the credentials, the lock must be held.
+// Evicted sub-pools are returned to be closed once the lock is released.
+func (pg *PoolGroup) getUnsafe(creds DaemonServer) (*groupEntry, []*JobPool, error) {
+	if elem, ok := pg.pools[creds]; ok {
+		pg.lru.MoveToFront(elem)
+		return elem.Value.(*groupEntry), nil, nil
+	}
+
+	// a new sub-pool fails early if no connection would fit
+	evicted, ok := pg.makeRoomUnsafe(nil)
+	if !ok {
+		return nil, evicted, fmt.Errorf("connection limit of %v reached", pg.options.MaxConnections)
+	}
+
+	options := pg.options.Template
+	options.Creds = creds
+	options.Hosts = nil
+	pool, err := NewPool(options)
+	if err != nil {
+		return nil, evicted, err
+	}
+
+	entry := &groupEntry{creds: creds, pool: pool}
+	pool.reserve = func() (func(), error) {
+		return pg.reserve(entry)
+	}
+	pg.pools[creds] = pg.lru.PushFront(entry)
+	return entry, evicted, nil
+}
+
+// reserves a slot for a connection of the sub-pool, evicting idle sub-pools
+// to make room for it. The returned function releases the slot.
+func (pg *PoolGroup) reserve(entry *groupEntry) (func(), error) {
+	pg.lock.Lock()
+	evicted, ok := pg.makeRoomUnsafe(entry)
+	if ok {
+		pg.reserved++
+	}
+	pg.lock.Unlock()
+
+	closePools(evicted)
+	if !ok {
+		return nil, fmt.Errorf("connection limit of %v reached", pg.options.MaxConnections)
+	}
+
+	return func() {
+		pg.lock.Lock()
+		pg.reserved--
+		pg.lock.Unlock()
+	}, nil
+}
+
+// evicts idle sub-pools other than keep until another connection fits,
+// the lock must be held. The limit counts the open connections of all
+// sub-pools and the reserved slots. It reports whether the connection fits.
+func (pg *PoolGroup) makeRoomUnsafe(keep *groupEntry) ([]*JobPool, bool) {
+	limit := pg.options.MaxConnections
+	if limit <= 0 {
+		return nil, true
+	}
+
+	open := pg.reserved
+	for elem := pg.lru.Front(); elem != nil; elem = elem.Next() {
+		open += elem.Value.(*groupEntry).pool.openConnections()
+	}
+
+	var evicted []*JobPool
+	for elem := pg.lru.Back(); elem != nil && open+1 > limit; {
+		prev := elem.Prev()
+		entry := elem.Value.(*groupEntry)
+		if entry != keep && entry.active == 0 && entry.pool.Stats().InUse == 0 {
+			open -= entry.pool.openConnections()
+			evicted = append(evicted, entry.pool)
+			pg.lru.Remove(elem)
+			delete(pg.pools, entry.creds)
+		}
+		elem = prev
+	}
+	return evicted, open+1 <= limit
+}
+
+// closes the pools, without holding the lock of the group
+func closePools(pools []*JobPool) {
+	for _, pool := range pools {
+		pool.Close()
+	}
+}
@@ -0,0 +1,140 @@
+package mapepire
+
+import "testing"
+
+func TestPoolGroupEviction(t *testing.T) {
+	group, err := NewPoolGroup(PoolGroupOptions{
+		Template:       PoolOptions{MaxSize: 2, StartingSize: 1},
+		MaxConnections: 4,
+	})
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+
+	alice := DaemonServer{Host: "localhost", User: "alice", Password: "pw"}
+	bob := DaemonServer{Host: "localhost", User: "bob", Password: "pw"}
+	carol := DaemonServer{Host: "localhost", User: "carol", Password: "pw"}
+
+	alicePool, _ := group.Get(alice)
+	alicePool.stats.connections.Store(2)
+	bobPool, _ := group.Get(bob)
+	bobPool.stats.connections.Store(2)
+	have, _ := group.Get(alice)
+	if have != alicePool {
+		t.Errorf("should receive the same pool")
+	}
+
+	// all 4 connections are open, bob was least recently used and is evicted
+	_, err = group.Get(carol)
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if group.Len() != 2 {
+		t.Errorf("have %v pools, want 2", group.Len())
+	}
+	if !bobPool.isClosing() {
+		t.Errorf("bob's pool should be evicted")
+	}
+	if alicePool.isClosing() {
+		t.Errorf("alice's pool should be kept")
+	}
+	group.Close()
+}
+
+func TestPoolGroupCountsConnections(t *testing.T) {
+	group, _ := NewPoolGroup(PoolGroupOptions{
+		Template:       PoolOptions{MaxSize: 2, StartingSize: 1},
+		MaxConnections: 4,
+	})
+
+	// each pool uses one of its two connections, so all four fit
+	for _, user := range []string{"alice", "bob", "carol", "dave"} {
+		pool, err := group.Get(DaemonServer{Host: "localhost", User: user, Password: "pw"})
+		if err != nil {
+			t.Fatalf("should not throw error")
+		}
+		pool.stats.connections.Store(1)
+	}
+	if group.Len() != 4 {
+		t.Errorf("have %v pools, want 4", group.Len())
+	}
+	group.Close()
+}
+
+func TestPoolGroupLimit(t *testing.T) {
+	group, _ := NewPoolGroup(PoolGroupOptions{
+		Template:       PoolOptions{MaxSize: 2, StartingSize: 1},
+		MaxConnections: 2,
+	})
+
+	alice := DaemonServer{Host: "localhost", User: "alice", Password: "pw"}
+	bob := DaemonServer{Host: "localhost", User: "bob", Password: "pw"}
+
+	pool, _ := group.Get(alice)
+	pool.stats.connections.Store(2)
+	group.lru.Front().Value.(*groupEntry).active++
+
+	_, err := group.Get(bob)
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// Concurrent connects reserve their slots of the connection limit
+func TestPoolGroupReservesConnections(t *testing.T) {
+	group, _ := NewPoolGroup(PoolGroupOptions{
+		Template:       PoolOptions{MaxSize: 2, StartingSize: 1},
+		MaxConnections: 2,
+	})
+
+	pool, _ := group.Get(DaemonServer{Host: "localhost", User: "alice", Password: "pw"})
+	pool.stats.connections.Store(1)
+
+	release, err := pool.reserveConnection()
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	_, err = pool.reserveConnection()
+	if err == nil {
+		t.Errorf("should throw error while the last slot is reserved")
+	}
+
+	release()
+	release, err = pool.reserveConnection()
+	if err != nil {
+		t.Errorf("should not throw error once the slot is released")
+	} else {
+		release()
+	}
+	group.Close()
+}
+
+// A connect evicts idle sub-pools to make room
+func TestPoolGroupReserveEvicts(t *testing.T) {
+	group, _ := NewPoolGroup(PoolGroupOptions{
+		Template:       PoolOptions{MaxSize: 2, StartingSize: 1},
+		MaxConnections: 2,
+	})
+
+	alicePool, _ := group.Get(DaemonServer{Host: "localhost", User: "alice", Password: "pw"})
+	alicePool.stats.connections.Store(1)
+	bobPool, _ := group.Get(DaemonServer{Host: "localhost", User: "bob", Password: "pw"})
+	bobPool.stats.connections.Store(1)
+
+	release, err := alicePool.reserveConnection()
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	release()
+	if !bobPool.isClosing() {
+		t.Errorf("bob's pool should be evicted")
+	}
+	group.Close()
+}
+
+func TestNewPoolGroupInvalid(t *testing.T) {
+	_, err := NewPoolGroup(PoolGroupOptions{Template: PoolOptions{MaxSize: 5, StartingSize: 1}, MaxConnections: 3})
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
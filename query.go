@@ -1,7 +1,12 @@
 package mapepire
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 )
@@ -12,19 +17,30 @@ type QueryOptions struct {
 	Parameters  [][]any // Parameters, if any
 	TerseResult bool    // Whether the result returns in terse format
 	IsCLcommand bool    // Whether the command is a CL command
+	Timeout     int     // Query time limit on the server (in seconds, 0 for no limit)
+	ReadOnly    bool    // Whether the query only reads data, used by the Router
+	Priority    int     // Priority for the limits of a pool: PRIORITY_BATCH, PRIORITY_NORMAL or PRIORITY_INTERACTIVE
+
+	AdaptiveFetch bool // Whether FetchNext adjusts the rows per request to the size of the results
+	TargetBytes   int  // Byte budget per message of adaptive fetching (DEFAULT_TARGET_BYTES if not set)
+	TargetLatency int  // Round-trip time per message of adaptive fetching (in seconds, DEFAULT_TARGET_LATENCY if not set)
 }
 
 // Represents a SQL Query that can be executed and managed within a SQL job
 type Query struct {
-	ID          string       // The unique identifier
-	clCommand   string       // CL command
-	sqlQuery    string       // SQL query
-	parameters  string       // Parameters, if any
-	terse       bool         // Whether the result returns in terse format
-	rowsToFetch string       // The amount of rows to fetch
-	prepared    bool         // Whether the query has been prepared
-	job         *SQLJob      // Pointer to the SQL Job
-	state       atomic.Int32 // The current state of the query
+	ID          string           // The unique identifier
+	clCommand   string           // CL command
+	sqlQuery    string           // SQL query
+	parameters  string           // Parameters, if any
+	terse       bool             // Whether the result returns in terse format
+	rowsToFetch string           // The amount of rows to fetch
+	prepared    bool             // Whether the query has been prepared
+	timeout     int              // Query time limit on the server (in seconds)
+	adaptive    *adaptiveFetcher // Adjusts the rows per request, if adaptive fetching is enabled
+	job         *SQLJob          // Pointer to the SQL Job
+	state       atomic.Int32     // The current state of the query
+	running     atomic.Bool      // Whether a request of the query is running
+	canceled    atomic.Bool      // Whether the query has been canceled
 }
 
 // Represents a query list managed by the job
@@ -33,6 +49,11 @@ type queryList struct {
 	lock sync.Mutex // Mutex
 }
 
+const (
+	SQLSTATE_CANCELED         = "57014"
+	SQLSTATE_RESOURCE_LIMIT   = "57005"
+	SQLRC_QUERY_TIME_EXCEEDED = -666
+)
 const (
 	STATE_RUN_DONE = iota
 	STATE_RUN_MORE_DATA
@@ -80,6 +101,21 @@ func (ql *queryList) cleanup() {
 	ql.list = newList
 }
 
+// closes all open cursors and empties the list
+func (ql *queryList) closeAll() error {
+	ql.lock.Lock()
+	defer ql.lock.Unlock()
+
+	var err error
+	for _, query := range ql.list {
+		if query.state.Load() == STATE_RUN_MORE_DATA {
+			err = errors.Join(err, query.sqlCloseUnsafe(query.ID))
+		}
+	}
+	ql.list = []*Query{}
+	return err
+}
+
 // Executes the query/command and returns the results
 func (q *Query) Execute() (*ServerResponse, error) {
 	q.job.setJobStatus(JOBSTATUS_BUSY)
@@ -90,22 +126,118 @@ func (q *Query) Execute() (*ServerResponse, error) {
 		return &ServerResponse{ID: q.ID}, err
 	}
 
+	request := q.request()
+	if q.clCommand != "" {
+		return q.sendCommand(request)
+	}
+
+	q.job.trackStatement(q.sqlQuery)
+	resp, err := q.sendWithTimeLimit(request)
+	if err == nil {
+		q.job.trackStatementDone(q.sqlQuery)
+	}
+	return resp, err
+}
+
+// receives the request executing the query/command
+func (q *Query) request() *serverRequest {
 	jsonreq := func() string {
 		if q.clCommand != "" {
-			return fmt.Sprintf(`{"id":"%s","type":"cl","cmd":"%s","terse":%t}`, q.ID, q.clCommand, q.terse)
+			return fmt.Sprintf(`{"id":"%s","type":"cl","cmd":%s,"terse":%t}`, q.ID, jsonString(q.clCommand), q.terse)
 		}
 		if q.prepared {
-			return fmt.Sprintf(`{"id":"%s","type":"prepare_sql_execute","sql":"%s","parameters":%s,"rows":"%s","terse":%t}`, q.ID, q.sqlQuery, q.parameters, q.rowsToFetch, q.terse)
+			return fmt.Sprintf(`{"id":"%s","type":"prepare_sql_execute","sql":%s,"parameters":%s,"rows":"%s","terse":%t}`, q.ID, jsonString(q.sqlQuery), q.parameters, q.rowsToFetch, q.terse)
 		}
-		return fmt.Sprintf(`{"id":"%s","type":"sql","sql":"%s","rows":"%s","terse":%t}`, q.ID, q.sqlQuery, q.rowsToFetch, q.terse)
+		return fmt.Sprintf(`{"id":"%s","type":"sql","sql":%s,"rows":"%s","terse":%t}`, q.ID, jsonString(q.sqlQuery), q.rowsToFetch, q.terse)
 	}()
 
-	request := &serverRequest{
+	return &serverRequest{
 		id:      q.ID,
 		jsonreq: jsonreq,
+		terse:   q.terse,
+	}
+}
+
+// Represents the query time limits of a job
+type timeLimits struct {
+	job   string     // Limit of the job set with CHGQRYA, *SYSVAL if not changed
+	query string     // Limit set for queries with a timeout, empty while the one of the job applies
+	lock  sync.Mutex // Mutex
+}
+
+// Matches a CL command changing the query time limit of the job
+var timeLimitCommand = regexp.MustCompile(`(?i)^\s*(?:\S+/)?CHGQRYA\b.*\bQRYTIMLMT\(\s*([^)\s]+)\s*\)`)
+
+// sends the request of a CL command.
+// A query time limit set by the command becomes the limit of the job.
+func (q *Query) sendCommand(request *serverRequest) (*ServerResponse, error) {
+	limits := &q.job.timeLimits
+	limits.lock.Lock()
+	defer limits.lock.Unlock()
+
+	resp, err := q.sendRequest(request)
+	if err == nil {
+		if match := timeLimitCommand.FindStringSubmatch(q.clCommand); match != nil {
+			limits.job = strings.ToUpper(match[1])
+			limits.query = ""
+		}
+	}
+	return resp, err
+}
+
+// sends the request with the query time limit of the query on the server.
+// The limit is only changed if it differs from the one set before, queries
+// without a timeout run with the limit of the job.
+func (q *Query) sendWithTimeLimit(request *serverRequest) (*ServerResponse, error) {
+	limits := &q.job.timeLimits
+	limits.lock.Lock()
+	defer limits.lock.Unlock()
+
+	limit := ""
+	if q.timeout > 0 {
+		limit = strconv.Itoa(q.timeout)
+	}
+	err := q.job.useTimeLimit(limit)
+	if err != nil {
+		q.job.setJobStatus(JOBSTATUS_ERROR)
+		return &ServerResponse{ID: q.ID}, err
 	}
 
-	return q.sendRequest(request)
+	resp, err := q.sendRequest(request)
+	if err != nil && q.timeout > 0 && (resp.SqlRC == SQLRC_QUERY_TIME_EXCEEDED || resp.SqlState == SQLSTATE_RESOURCE_LIMIT) {
+		err = &TimeoutError{Method: "Execute()", Message: err.Error()}
+	}
+	return resp, err
+}
+
+// sets the query time limit on the server if it differs from the one set before,
+// the limit of the job if empty. The lock of the time limits must be held.
+func (s *SQLJob) useTimeLimit(limit string) error {
+	limits := &s.timeLimits
+	if limit == limits.query {
+		return nil
+	}
+
+	value := limit
+	if value == "" {
+		value = limits.job
+	}
+	if value == "" {
+		value = "*SYSVAL"
+	}
+
+	// the command is sent without Execute, so it does not change the limit of the job
+	query, err := s.QueryWithOptions(fmt.Sprintf("CHGQRYA QRYTIMLMT(%s)", value), QueryOptions{IsCLcommand: true})
+	if err != nil {
+		return err
+	}
+	_, err = query.sendRequest(query.request())
+	if err != nil {
+		return err
+	}
+	limits.query = limit
+	s.setJobStatus(JOBSTATUS_BUSY)
+	return nil
 }
 
 // Fetch more rows from a previous request with the ID
@@ -133,6 +265,7 @@ func (q *Query) FetchMore(contID string, rows string) (*ServerResponse, error) {
 	request := &serverRequest{
 		id:      q.ID,
 		jsonreq: jsonreq,
+		terse:   q.terse,
 	}
 
 	response, err := q.sendRequest(request)
@@ -179,11 +312,49 @@ func (q *Query) sqlCloseUnsafe(contID string) error {
 	return nil
 }
 
-// sends the request and sets the query state
+// Cancels the running statement of the query.
+// The cancel request is sent on a separate connection to the server,
+// the blocked Execute or FetchMore returns a CancelError.
+func (q *Query) Cancel() error {
+	if !q.running.Load() {
+		return fmt.Errorf("query is not running")
+	}
+	if q.job.Jobname == "" {
+		return fmt.Errorf("need the name of the job")
+	}
+	q.canceled.Store(true)
+
+	cancelJob := NewSQLJob(q.job.ID + " cancel")
+	err := cancelJob.Connect(q.job.daemon)
+	if err != nil {
+		q.canceled.Store(false)
+		return err
+	}
+	defer cancelJob.Close()
+
+	query, err := cancelJob.Query(fmt.Sprintf("CALL QSYS2.CANCEL_SQL('%s')", q.job.Jobname))
+	if err == nil {
+		_, err = query.Execute()
+	}
+	if err != nil {
+		q.canceled.Store(false)
+	}
+	return err
+}
+
+// sends the request and sets the query state.
+// A cancel only applies to the request it interrupted.
 func (q *Query) sendRequest(request *serverRequest) (*ServerResponse, error) {
+	q.canceled.Store(false)
+	q.running.Store(true)
 	resp, err := q.job.send(*request)
+	q.running.Store(false)
+	canceled := q.canceled.Swap(false)
 	if err != nil {
 		q.job.setJobStatus(JOBSTATUS_ERROR)
+		if canceled || resp.SqlState == SQLSTATE_CANCELED {
+			err = &CancelError{Method: "sendRequest()", Message: err.Error()}
+		}
 		return resp, err
 	}
 
@@ -192,8 +363,15 @@ func (q *Query) sendRequest(request *serverRequest) (*ServerResponse, error) {
 	} else if resp.Success && !resp.IsDone {
 		q.state.Store(STATE_RUN_MORE_DATA)
 	}
+	q.observe(resp)
 	q.job.queryList.cleanup()
 
 	q.job.setJobStatus(JOBSTATUS_READY)
 	return resp, nil
 }
+
+// encodes the text as a JSON string, so quotes in SQL and CL text can not end the string
+func jsonString(text string) string {
+	encoded, _ := json.Marshal(text)
+	return string(encoded)
+}
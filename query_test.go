@@ -1,9 +1,11 @@
 package mapepire
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"testing"
+	"time"
 )
 
 func initSQLTable(command string, queryops2 QueryOptions) (*SQLJob, *Query) {
@@ -709,3 +711,115 @@ func TestSQLCloseInvalid(t *testing.T) {
 		t.Errorf("should throw error")
 	}
 }
+
+// Cancel a running query
+func TestCancel(t *testing.T) {
+	job := NewSQLJob("test")
+	job.Connect(server)
+
+	query, _ := job.Query("SELECT COUNT(*) FROM QSYS2.SYSCOLUMNS A, QSYS2.SYSCOLUMNS B")
+	result := make(chan error, 1)
+	go func() {
+		_, err := query.Execute()
+		result <- err
+	}()
+
+	time.Sleep(time.Second)
+	err := query.Cancel()
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	var cancelErr *CancelError
+	if have := <-result; !errors.As(have, &cancelErr) {
+		t.Errorf("have %v, want CancelError", have)
+	}
+}
+
+// Cancel a query that is not running
+func TestCancelInvalid(t *testing.T) {
+	job := NewSQLJob("test")
+	query, _ := job.Query("SELECT * FROM TEMPTEST")
+
+	err := query.Cancel()
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// A cancel does not apply to later requests of the query
+func TestCancelCleared(t *testing.T) {
+	job := NewSQLJob("test")
+	query, _ := job.Query("SELECT * FROM TEMPTEST")
+	query.canceled.Store(true)
+
+	_, err := query.sendRequest(&serverRequest{id: query.ID})
+	var cancelErr *CancelError
+	if errors.As(err, &cancelErr) {
+		t.Errorf("have %v, want no CancelError", err)
+	}
+	if query.canceled.Load() {
+		t.Errorf("cancel should be cleared")
+	}
+}
+
+// Execute SQL exceeding the query time limit
+func TestExecuteTimeout(t *testing.T) {
+	job := NewSQLJob("test")
+	job.Connect(server)
+
+	query, _ := job.QueryWithOptions("SELECT COUNT(*) FROM QSYS2.SYSCOLUMNS A, QSYS2.SYSCOLUMNS B, QSYS2.SYSCOLUMNS C", QueryOptions{Timeout: 1})
+	_, err := query.Execute()
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("have %v, want TimeoutError", err)
+	}
+}
+
+func TestJSONString(t *testing.T) {
+	have := jsonString(`SNDMSG MSG('say "hi"\now') TOUSR(QSYSOPR)`)
+	want := `"SNDMSG MSG('say \"hi\"\\now') TOUSR(QSYSOPR)"`
+	if have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+// Recognize CL commands changing the query time limit of the job
+func TestTimeLimitCommand(t *testing.T) {
+	tests := map[string]string{
+		"CHGQRYA QRYTIMLMT(60)":                  "60",
+		"chgqrya qrytimlmt( *nomax )":            "*nomax",
+		"QSYS/CHGQRYA JOB(*) QRYTIMLMT(*SYSVAL)": "*SYSVAL",
+		"CHGQRYA DEGREE(*MAX)":                   "",
+		"CHGJOB QRYTIMLMT(60)":                   "",
+	}
+	for command, want := range tests {
+		have := ""
+		if match := timeLimitCommand.FindStringSubmatch(command); match != nil {
+			have = match[1]
+		}
+		if have != want {
+			t.Errorf("have %q, want %q for %v", have, want, command)
+		}
+	}
+}
+
+// Only change the query time limit if it differs
+func TestUseTimeLimit(t *testing.T) {
+	job := NewSQLJob("test")
+	if err := job.useTimeLimit(""); err != nil {
+		t.Errorf("should not change the limit of the job")
+	}
+
+	job.timeLimits.query = "30"
+	if err := job.useTimeLimit("30"); err != nil {
+		t.Errorf("should not change the same limit")
+	}
+	if err := job.useTimeLimit("10"); err == nil {
+		t.Errorf("should throw error")
+	}
+	if job.timeLimits.query != "30" {
+		t.Errorf("have %v, want the limit to stay 30", job.timeLimits.query)
+	}
+}
@@ -0,0 +1,140 @@
+package mapepire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Routes statements across a primary and a replica pool.
+// Read-only statements run on the replica, everything else on the primary.
+type Router struct {
+	primary *JobPool // Pool of the primary system
+	replica *JobPool // Pool of the replica system
+}
+
+// Create a new router for the primary and replica pool
+func NewRouter(primary *JobPool, replica *JobPool) (*Router, error) {
+	if primary == nil || replica == nil {
+		return nil, fmt.Errorf("primary and replica pool required")
+	}
+	return &Router{primary: primary, replica: replica}, nil
+}
+
+// Execute a SQL query on the primary or replica pool
+func (r *Router) ExecuteSQL(sql string) (*ServerResponse, error) {
+	return r.ExecuteSQLWithOptions(sql, QueryOptions{})
+}
+
+// Execute a SQL query with options on the primary or replica pool.
+// Read-only statements fall back to the primary if the replica cannot be reached,
+// its circuit breaker is open or no job is available in time.
+func (r *Router) ExecuteSQLWithOptions(command string, queryops QueryOptions) (*ServerResponse, error) {
+	if !isReadOnly(command, queryops) {
+		return r.primary.ExecuteSQLWithOptions(command, queryops)
+	}
+
+	resp, err := r.replica.ExecuteSQLWithOptions(command, queryops)
+	if replicaUnavailable(err) {
+		return r.primary.ExecuteSQLWithOptions(command, queryops)
+	}
+	return resp, err
+}
+
+// reports whether the error means the replica could not run the statement at all
+func replicaUnavailable(err error) bool {
+	var wsErr *WebsocketError
+	var circuitErr *CircuitOpenError
+	var exhaustedErr *PoolExhaustedError
+	return errors.As(err, &wsErr) || errors.As(err, &circuitErr) || errors.As(err, &exhaustedErr)
+}
+
+// Borrow a job from the replica pool for read-only work
+func (r *Router) WithReadJob(ctx context.Context, fn func(job *SQLJob) error) error {
+	return r.replica.WithJob(ctx, fn)
+}
+
+// Borrow a job from the primary pool and run fn in a transaction.
+// The transaction is committed if fn succeeds and rolled back otherwise.
+func (r *Router) WithTransaction(ctx context.Context, fn func(job *SQLJob) error) error {
+	return r.primary.WithJob(ctx, func(job *SQLJob) error {
+		err := fn(job)
+		if err != nil {
+			return err
+		}
+		return job.EndTransaction(TRANSACTION_COMMIT)
+	})
+}
+
+// Receive the primary pool
+func (r *Router) Primary() *JobPool {
+	return r.primary
+}
+
+// Receive the replica pool
+func (r *Router) Replica() *JobPool {
+	return r.replica
+}
+
+// reports whether a statement only reads data.
+// SELECT, VALUES and WITH statements are read-only, unless they lock rows
+// or change data through a data-change table reference.
+func isReadOnly(command string, options QueryOptions) bool {
+	if options.IsCLcommand {
+		return false
+	}
+	if options.ReadOnly {
+		return true
+	}
+
+	sql := strings.ToUpper(stripSQLComments(command))
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch fields[0] {
+	case "SELECT", "VALUES", "WITH":
+	default:
+		return false
+	}
+
+	normalized := " " + strings.Join(fields, " ") + " "
+	for _, keyword := range []string{" FOR UPDATE", " FINAL TABLE", " NEW TABLE", " OLD TABLE", " INTO "} {
+		if strings.Contains(normalized, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// removes line and block comments from a SQL statement
+func stripSQLComments(sql string) string {
+	var b strings.Builder
+	inString := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			inString = !inString
+			b.WriteByte(c)
+		case !inString && c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			b.WriteByte(' ')
+		case !inString && c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package mapepire
+
+import "testing"
+
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		command string
+		options QueryOptions
+		want    bool
+	}{
+		{"SELECT * FROM employee", QueryOptions{}, true},
+		{"  values current date", QueryOptions{}, true},
+		{"WITH t AS (SELECT 1 FROM SYSIBM.SYSDUMMY1) SELECT * FROM t", QueryOptions{}, true},
+		{"(SELECT 1 FROM SYSIBM.SYSDUMMY1)", QueryOptions{}, true},
+		{"-- comment\nSELECT 1 FROM SYSIBM.SYSDUMMY1", QueryOptions{}, true},
+		{"/* report */ SELECT 1 FROM SYSIBM.SYSDUMMY1", QueryOptions{}, true},
+		{"SELECT * FROM employee WHERE name = '--'", QueryOptions{}, true},
+		{"SELECT * FROM employee FOR UPDATE", QueryOptions{}, false},
+		{"SELECT * FROM FINAL TABLE (INSERT INTO employee VALUES (1))", QueryOptions{}, false},
+		{"INSERT INTO employee VALUES (1)", QueryOptions{}, false},
+		{"UPDATE employee SET name = 'Max'", QueryOptions{}, false},
+		{"CALL MYLIB.REPORT()", QueryOptions{}, false},
+		{"CALL MYLIB.REPORT()", QueryOptions{ReadOnly: true}, true},
+		{"DSPLIB MYLIB", QueryOptions{IsCLcommand: true, ReadOnly: true}, false},
+		{"", QueryOptions{}, false},
+	}
+
+	for _, test := range tests {
+		have := isReadOnly(test.command, test.options)
+		if have != test.want {
+			t.Errorf("%q: have %v, want %v", test.command, have, test.want)
+		}
+	}
+}
+
+func TestNewRouterInvalid(t *testing.T) {
+	_, err := NewRouter(nil, nil)
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+func TestReplicaUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&WebsocketError{Method: "send()"}, true},
+		{&CircuitOpenError{Method: "allow()"}, true},
+		{&PoolExhaustedError{Method: "waitForJob()"}, true},
+		{&ServerError{Method: "checkJsonErr()"}, false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if have := replicaUnavailable(test.err); have != test.want {
+			t.Errorf("%v: have %v, want %v", test.err, have, test.want)
+		}
+	}
+}
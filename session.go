@@ -0,0 +1,121 @@
+package mapepire
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Represents the session state of a job
+type sessionState struct {
+	schema  string   // The current schema
+	path    string   // The current SQL path
+	curlib  string   // The current library
+	libList []string // The user portion of the library list
+	qtemp   int      // The number of objects in QTEMP
+}
+
+// Receive the current session state of the job in a single round trip
+func (s *SQLJob) getSessionState() (*sessionState, error) {
+	query, err := s.Query(
+		"SELECT CURRENT SCHEMA AS SCHEMA, CURRENT PATH AS PATH, " +
+			"(SELECT LISTAGG(TRIM(SCHEMA_NAME), ' ') WITHIN GROUP (ORDER BY ORDINAL_POSITION) FROM QSYS2.LIBRARY_LIST_INFO WHERE TYPE = 'USER') AS LIBL, " +
+			"(SELECT MAX(TRIM(SCHEMA_NAME)) FROM QSYS2.LIBRARY_LIST_INFO WHERE TYPE = 'CURRENT') AS CURLIB, " +
+			"(SELECT COUNT(*) FROM TABLE(QSYS2.OBJECT_STATISTICS('QTEMP', '*ALL'))) AS QTEMP " +
+			"FROM SYSIBM.SYSDUMMY1",
+	)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := query.Execute()
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("no session state received")
+	}
+	return newSessionState(resp.Data[0]), nil
+}
+
+// receives the session state of a row
+func newSessionState(row map[string]any) *sessionState {
+	text := func(name string) string {
+		if row[name] == nil {
+			return ""
+		}
+		return strings.TrimSpace(formatValue(row[name]))
+	}
+
+	state := &sessionState{
+		schema:  text("SCHEMA"),
+		path:    text("PATH"),
+		curlib:  text("CURLIB"),
+		libList: strings.Fields(text("LIBL")),
+	}
+	fmt.Sscan(text("QTEMP"), &state.qtemp)
+	return state
+}
+
+// Resets the session of the job to the state after connecting.
+// QTEMP is cleared and the current schema, SQL path and library list are
+// restored, each only if it changed. Cursors and transactions are handled
+// by the cleanup of the job before.
+// Global variables are not reset, use PoolOptions.ResetHook for those.
+func (s *SQLJob) resetSession() error {
+	if s.session == nil {
+		return fmt.Errorf("no session state to reset to")
+	}
+
+	current, err := s.getSessionState()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range s.session.resetCommands(current) {
+		err := s.runCommand(c.command, c.isCL)
+		if err != nil {
+			return fmt.Errorf("error resetting session with %q: %w", c.command, err)
+		}
+	}
+	return nil
+}
+
+// Represents a command resetting part of the session
+type resetCommand struct {
+	command string // SQL statement or CL command
+	isCL    bool   // Whether the command is a CL command
+}
+
+// receives the commands restoring the state from the current state
+func (state *sessionState) resetCommands(current *sessionState) []resetCommand {
+	var commands []resetCommand
+	if current.qtemp > 0 {
+		commands = append(commands, resetCommand{"CLRLIB LIB(QTEMP)", true})
+	}
+	if current.curlib != state.curlib || strings.Join(current.libList, " ") != strings.Join(state.libList, " ") {
+		curlib := state.curlib
+		if curlib == "" {
+			curlib = "*CRTDFT"
+		}
+		libList := strings.Join(state.libList, " ")
+		if libList == "" {
+			libList = "*NONE"
+		}
+		commands = append(commands, resetCommand{fmt.Sprintf("CHGLIBL LIBL(%s) CURLIB(%s)", libList, curlib), true})
+	}
+	if current.schema != state.schema {
+		commands = append(commands, resetCommand{fmt.Sprintf("SET SCHEMA = '%s'", strings.ReplaceAll(state.schema, "'", "''")), false})
+	}
+	if current.path != state.path {
+		commands = append(commands, resetCommand{"SET PATH = " + sqlPath(state.path), false})
+	}
+	return commands
+}
+
+// converts the value of CURRENT PATH into a SET PATH operand,
+// the entries keep their delimiters
+func sqlPath(path string) string {
+	if path == "" || path == "*LIBL" {
+		return "*LIBL"
+	}
+	return path
+}
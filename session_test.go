@@ -0,0 +1,83 @@
+package mapepire
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSQLPath(t *testing.T) {
+	tests := map[string]string{
+		"":                       "*LIBL",
+		"*LIBL":                  "*LIBL",
+		`"QSYS","QSYS2","MYLIB"`: `"QSYS","QSYS2","MYLIB"`,
+		`"QSYS","My Lib"`:        `"QSYS","My Lib"`,
+	}
+	for path, want := range tests {
+		have := sqlPath(path)
+		if have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestResetCommands(t *testing.T) {
+	state := newSessionState(map[string]any{"SCHEMA": "USER1", "PATH": `"QSYS","QSYS2","USER1"`, "LIBL": "QGPL QTEMP", "CURLIB": nil, "QTEMP": 0.0})
+	if state.curlib != "" || len(state.libList) != 2 {
+		t.Errorf("have %+v", state)
+	}
+
+	current := *state
+	if commands := state.resetCommands(&current); len(commands) != 0 {
+		t.Errorf("have %v, want no commands for an unchanged session", commands)
+	}
+
+	current.schema = "OTHER"
+	current.qtemp = 3
+	commands := state.resetCommands(&current)
+	if len(commands) != 2 || commands[0].command != "CLRLIB LIB(QTEMP)" || commands[1].command != "SET SCHEMA = 'USER1'" {
+		t.Errorf("have %v, want CLRLIB and SET SCHEMA", commands)
+	}
+
+	current = *state
+	current.libList = []string{"QGPL"}
+	commands = state.resetCommands(&current)
+	if len(commands) != 1 || commands[0].command != "CHGLIBL LIBL(QGPL QTEMP) CURLIB(*CRTDFT)" {
+		t.Errorf("have %v, want CHGLIBL", commands)
+	}
+}
+
+func TestResetSession(t *testing.T) {
+	pool, err := NewPool(PoolOptions{Creds: server, MaxWaitTime: 1, MaxSize: 1, StartingSize: 1, ResetSession: true})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	var schema string
+	err = pool.WithJob(context.Background(), func(job *SQLJob) error {
+		schema = job.session.schema
+		query, err := job.Query("SET SCHEMA = 'QTEMP'")
+		if err != nil {
+			return err
+		}
+		_, err = query.Execute()
+		return err
+	})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	err = pool.WithJob(context.Background(), func(job *SQLJob) error {
+		state, err := job.getSessionState()
+		if err != nil {
+			return err
+		}
+		if state.schema != schema {
+			t.Errorf("have %v, want %v", state.schema, schema)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	pool.Close()
+}
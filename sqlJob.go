@@ -35,8 +35,11 @@ type SQLJob struct {
 	Status     string          // Status of the Job
 	Options    *TraceOptions   // Trace configuration options
 	daemon     DaemonServer    // Server daemon with connection details
-	query      *Query          // Pointer to the query
 	queryList  *queryList      // List of all open queries
+	session    *sessionState   // Session state after connecting, used to reset the job
+	statements statementCache  // Prepared statements by SQL
+	pending    atomic.Bool     // Whether statements may have changed data since the last commit or rollback
+	timeLimits timeLimits      // Query time limits set with CHGQRYA
 	connection *websocket.Conn // Websocket connection
 	counter    atomic.Uint32   // Atomic counter
 	writeMutex sync.Mutex      // Mutex
@@ -54,6 +57,10 @@ const (
 	DEFAULT_FETCH_SIZE = 100
 	MAX_FETCH_SIZE     = 1000
 )
+const (
+	TRANSACTION_COMMIT   = "COMMIT"
+	TRANSACTION_ROLLBACK = "ROLLBACK"
+)
 
 // Receive a new SQL job with the given ID
 func NewSQLJob(ID string) *SQLJob {
@@ -84,6 +91,11 @@ func (s *SQLJob) Connect(server DaemonServer) error {
 		return &WebsocketError{Method: "Connect()", Message: err.Error()}
 	}
 	s.connection = conn
+	s.dropStatementCache()
+	s.pending.Store(false)
+	s.timeLimits.lock.Lock()
+	s.timeLimits.job, s.timeLimits.query = "", ""
+	s.timeLimits.lock.Unlock()
 
 	var jsonreq string
 	if server.Technique != "" {
@@ -126,6 +138,7 @@ func (s *SQLJob) send(req serverRequest) (*ServerResponse, error) {
 
 	s.writeMutex.Lock()
 	defer s.writeMutex.Unlock()
+	start := time.Now()
 	if err := s.connection.WriteMessage(1, []byte(req.jsonreq)); err != nil {
 		msg := "WriteMessage(): " + err.Error()
 		return response, &WebsocketError{Method: "send()", Message: msg}
@@ -136,14 +149,19 @@ func (s *SQLJob) send(req serverRequest) (*ServerResponse, error) {
 		msg := "ReadMessage(): " + err.Error()
 		return response, &WebsocketError{Method: "send()", Message: msg}
 	}
+	latency := time.Since(start)
 
 	response.SqlRC, response.SqlState, response.Error = checkJsonErr(resp, s)
 	if response.Error != nil {
+		// keep the data of the failed request, e.g. the job log of a CL command
+		var failed struct{ Data []map[string]any }
+		json.Unmarshal(resp, &failed)
+		response.Data = failed.Data
 		return response, response.Error
 	}
 
 	// Only works if data is received in terse format
-	if s.query != nil && s.query.terse {
+	if req.terse {
 		resp = []byte(strings.Replace(string(resp), `"data":[[`, `"terse_data":[[`, 1))
 	}
 
@@ -156,6 +174,8 @@ func (s *SQLJob) send(req serverRequest) (*ServerResponse, error) {
 	if s.Jobname != "" {
 		response.Job = s.Jobname
 	}
+	response.size = len(resp)
+	response.latency = latency
 
 	return response, nil
 }
@@ -250,8 +270,12 @@ func (s *SQLJob) QueryWithOptions(command string, options QueryOptions) (*Query,
 		parameters:  jsonParams,
 		rowsToFetch: rows,
 		terse:       options.TerseResult,
+		timeout:     options.Timeout,
 		job:         s,
 	}
+	if options.AdaptiveFetch {
+		query.adaptive = newAdaptiveFetcher(options.Rows, options.TargetBytes, options.TargetLatency)
+	}
 	query.state.Store(STATE_NOT_YET_RUN)
 
 	if options.Parameters != nil {
@@ -266,7 +290,6 @@ func (s *SQLJob) QueryWithOptions(command string, options QueryOptions) (*Query,
 
 	s.queryList.addQuery(query)
 
-	s.query = query
 	return query, nil
 }
 
@@ -430,7 +453,6 @@ func (s *SQLJob) Close() error {
 
 	s.connection = nil
 	s.Options = nil
-	s.query = nil
 	return nil
 }
 
@@ -476,6 +498,95 @@ func (s *SQLJob) GetVersion() (string, error) {
 	return response.Version, nil
 }
 
+// Ends the current transaction with a COMMIT or ROLLBACK
+func (s *SQLJob) EndTransaction(endType string) error {
+	if endType != TRANSACTION_COMMIT && endType != TRANSACTION_ROLLBACK {
+		return fmt.Errorf("transaction end type must be %v or %v", TRANSACTION_COMMIT, TRANSACTION_ROLLBACK)
+	}
+
+	query, err := s.Query(endType)
+	if err != nil {
+		return err
+	}
+	_, err = query.Execute()
+	return err
+}
+
+// runs a SQL statement or CL command
+func (s *SQLJob) runCommand(command string, isCL bool) error {
+	query, err := s.QueryWithOptions(command, QueryOptions{IsCLcommand: isCL})
+	if err != nil {
+		return err
+	}
+	_, err = query.Execute()
+	return err
+}
+
+// closes open cursors and rolls back pending transactions.
+// Only changes since the last commit or rollback without autocommit are rolled back,
+// so jobs that only read need no round trip.
+func (s *SQLJob) cleanup() error {
+	err := s.queryList.closeAll()
+	if err != nil {
+		return err
+	}
+
+	if !s.pending.Load() || s.autoCommit() {
+		return nil
+	}
+	return s.EndTransaction(TRANSACTION_ROLLBACK)
+}
+
+// records whether a SQL statement may leave changes pending
+func (s *SQLJob) trackStatement(sql string) {
+	if !isReadOnly(sql, QueryOptions{}) && !isTransactionEnd(sql) {
+		s.pending.Store(true)
+	}
+}
+
+// records a successful SQL statement, a commit or rollback ends the transaction
+func (s *SQLJob) trackStatementDone(sql string) {
+	if isTransactionEnd(sql) {
+		s.pending.Store(false)
+	}
+}
+
+// reports whether the SQL statement is a COMMIT or ROLLBACK (not to a savepoint)
+func isTransactionEnd(sql string) bool {
+	fields := strings.Fields(strings.ToUpper(stripSQLComments(sql)))
+	if len(fields) == 0 || (fields[0] != "COMMIT" && fields[0] != "ROLLBACK") {
+		return false
+	}
+	for _, field := range fields {
+		if field == "TO" {
+			return false
+		}
+	}
+	return true
+}
+
+// Receive a JDBC property of the job, empty if not set
+func (s *SQLJob) property(name string) string {
+	for _, property := range strings.Split(s.daemon.Properties, ";") {
+		key, value, found := strings.Cut(property, "=")
+		if found && strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// reports whether every statement is committed, which is the JDBC default
+func (s *SQLJob) autoCommit() bool {
+	return !strings.EqualFold(s.property("auto commit"), "false")
+}
+
+// reports whether changes can be rolled back, which needs commitment control
+// without autocommit
+func (s *SQLJob) commitmentControl() bool {
+	return !s.autoCommit() && !strings.EqualFold(s.property("transaction isolation"), "none")
+}
+
 // Receive the name of the Job
 func (s *SQLJob) getDBJob() (string, error) {
 	if s.ID == "" {
@@ -415,3 +415,63 @@ func TestGetStatus(t *testing.T) {
 		t.Errorf("wrong status")
 	}
 }
+
+func TestTrackStatement(t *testing.T) {
+	job := NewSQLJob("test")
+	job.trackStatement("SELECT * FROM SAMPLE.EMPLOYEE")
+	if job.pending.Load() {
+		t.Errorf("read should not leave changes pending")
+	}
+	job.trackStatement("UPDATE SAMPLE.EMPLOYEE SET SALARY = 0")
+	if !job.pending.Load() {
+		t.Errorf("update should leave changes pending")
+	}
+	job.trackStatementDone("ROLLBACK TO SAVEPOINT A")
+	if !job.pending.Load() {
+		t.Errorf("rollback to savepoint should not end the transaction")
+	}
+	job.trackStatementDone("commit work")
+	if job.pending.Load() {
+		t.Errorf("commit should end the transaction")
+	}
+}
+
+// Cleanup of a job that only read needs no round trip
+func TestCleanupWithoutChanges(t *testing.T) {
+	job := NewSQLJob("test")
+	job.daemon.Properties = "auto commit=false"
+	if err := job.cleanup(); err != nil {
+		t.Errorf("should not throw error: %v", err)
+	}
+	job.pending.Store(true)
+	if err := job.cleanup(); err == nil {
+		t.Errorf("should try to roll back")
+	}
+}
+
+func TestAutoCommit(t *testing.T) {
+	job := NewSQLJob("test")
+	if !job.autoCommit() {
+		t.Errorf("should commit every statement by default")
+	}
+	job.daemon.Properties = "naming=system; Auto Commit = false;"
+	if job.autoCommit() {
+		t.Errorf("should not commit every statement")
+	}
+}
+
+func TestCommitmentControl(t *testing.T) {
+	tests := map[string]bool{
+		"":                  false,
+		"auto commit=false": true,
+		"auto commit=false;transaction isolation=none":        false,
+		"auto commit=true;transaction isolation=serializable": false,
+	}
+	for properties, want := range tests {
+		job := NewSQLJob("test")
+		job.daemon.Properties = properties
+		if have := job.commitmentControl(); have != want {
+			t.Errorf("have %v, want %v for %q", have, want, properties)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package mapepire
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Represents a prepared statement that can be executed repeatedly
+type Statement struct {
+	ID             string          // The unique identifier
+	SQL            string          // SQL of the statement
+	ParameterCount int             // The number of parameters of the statement
+	Parameters     []ParameterInfo // Metadata of the parameters
+	query          *Query          // Query used to prepare the statement
+	closed         atomic.Bool     // Whether the statement has been closed
+}
+
+// Represents the prepared statements of a job, by SQL
+type statementCache struct {
+	list map[string]*Statement // All prepared statements
+	lock sync.Mutex            // Mutex
+}
+
+// Prepares a SQL statement that can be executed repeatedly.
+// Statements are cached per job, preparing the same SQL again returns the cached statement.
+func (s *SQLJob) Prepare(sql string) (*Statement, error) {
+	return s.PrepareWithOptions(sql, QueryOptions{Rows: DEFAULT_FETCH_SIZE})
+}
+
+// Prepares a SQL statement with the given options (Rows and TerseResult)
+func (s *SQLJob) PrepareWithOptions(sql string, options QueryOptions) (*Statement, error) {
+	if sql == "" {
+		return nil, fmt.Errorf("SQL required")
+	}
+
+	s.statements.lock.Lock()
+	defer s.statements.lock.Unlock()
+
+	if stmt, ok := s.statements.list[sql]; ok && !stmt.closed.Load() {
+		return stmt, nil
+	}
+
+	var rows string
+	if options.Rows > 0 {
+		rows = fmt.Sprint(options.Rows)
+	}
+	if options.Rows > MAX_FETCH_SIZE {
+		rows = fmt.Sprint(MAX_FETCH_SIZE)
+	}
+	query := &Query{
+		ID:          s.getNewUniqueID(),
+		sqlQuery:    sql,
+		rowsToFetch: rows,
+		terse:       options.TerseResult,
+		prepared:    true,
+		job:         s,
+	}
+
+	jsonreq := fmt.Sprintf(`{"id":"%s","type":"prepare_sql","sql":%s,"terse":%t}`, query.ID, jsonString(sql), query.terse)
+	resp, err := s.send(serverRequest{id: query.ID, jsonreq: jsonreq, terse: query.terse})
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &Statement{
+		ID:             query.ID,
+		SQL:            sql,
+		ParameterCount: resp.ParameterCount,
+		query:          query,
+	}
+	if resp.Metadata != nil {
+		stmt.Parameters = resp.Metadata.Parameters
+	}
+
+	if s.statements.list == nil {
+		s.statements.list = make(map[string]*Statement)
+	}
+	s.statements.list[sql] = stmt
+	return stmt, nil
+}
+
+// Executes the prepared statement with the parameters
+func (st *Statement) Execute(parameters ...any) (*ServerResponse, error) {
+	job := st.query.job
+	if st.closed.Load() {
+		return &ServerResponse{ID: st.ID}, fmt.Errorf("statement has been closed")
+	}
+	if len(parameters) != st.ParameterCount {
+		return &ServerResponse{ID: st.ID}, fmt.Errorf("have %v parameters, want %v", len(parameters), st.ParameterCount)
+	}
+	if parameters == nil {
+		parameters = []any{}
+	}
+
+	params, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling to JSON: %v", err)
+	}
+
+	ID := job.getNewUniqueID()
+	jsonreq := fmt.Sprintf(`{"id":"%s","type":"execute","cont_id":"%s","parameters":%s,"rows":"%s","terse":%t}`, ID, st.ID, params, st.query.rowsToFetch, st.query.terse)
+
+	// statements run with the query time limit of the job
+	job.timeLimits.lock.Lock()
+	defer job.timeLimits.lock.Unlock()
+	err = job.useTimeLimit("")
+	if err != nil {
+		return &ServerResponse{ID: st.ID}, err
+	}
+
+	job.trackStatement(st.SQL)
+	resp, err := st.query.sendStatementRequest(&serverRequest{id: ID, jsonreq: jsonreq, terse: st.query.terse})
+	if err == nil {
+		job.trackStatementDone(st.SQL)
+	}
+	return resp, err
+}
+
+// Fetch more rows from the last execution of the statement
+func (st *Statement) FetchMore(rows string) (*ServerResponse, error) {
+	job := st.query.job
+	if st.closed.Load() {
+		return &ServerResponse{ID: st.ID}, fmt.Errorf("statement has been closed")
+	}
+
+	ID := job.getNewUniqueID()
+	jsonreq := fmt.Sprintf(`{"id":"%s","type":"sqlmore","cont_id":"%s","rows":"%s"}`, ID, st.ID, rows)
+
+	resp, err := st.query.sendStatementRequest(&serverRequest{id: ID, jsonreq: jsonreq, terse: st.query.terse})
+	if err != nil {
+		return resp, err
+	}
+	if resp.Success {
+		resp.HasResults = true
+	}
+	return resp, nil
+}
+
+// Closes the prepared statement and removes it from the cache
+func (st *Statement) Close() error {
+	job := st.query.job
+
+	job.statements.lock.Lock()
+	if job.statements.list[st.SQL] == st {
+		delete(job.statements.list, st.SQL)
+	}
+	job.statements.lock.Unlock()
+
+	if st.closed.Swap(true) {
+		return nil
+	}
+	return st.query.sqlCloseUnsafe(st.ID)
+}
+
+// Closes all prepared statements of the job
+func (s *SQLJob) ClearStatementCache() error {
+	s.statements.lock.Lock()
+	statements := s.statements.list
+	s.statements.list = nil
+	s.statements.lock.Unlock()
+
+	var errs error
+	for _, stmt := range statements {
+		errs = errors.Join(errs, stmt.Close())
+	}
+	return errs
+}
+
+// marks all prepared statements of the job as closed without closing them on
+// the server, e.g. because their connection has been replaced
+func (s *SQLJob) dropStatementCache() {
+	s.statements.lock.Lock()
+	statements := s.statements.list
+	s.statements.list = nil
+	s.statements.lock.Unlock()
+
+	for _, stmt := range statements {
+		stmt.closed.Store(true)
+	}
+}
+
+// sends a request of a prepared statement, which is not part of the query list
+func (q *Query) sendStatementRequest(request *serverRequest) (*ServerResponse, error) {
+	q.job.setJobStatus(JOBSTATUS_BUSY)
+	q.running.Store(true)
+	resp, err := q.job.send(*request)
+	q.running.Store(false)
+	if err != nil {
+		q.job.setJobStatus(JOBSTATUS_ERROR)
+		return resp, err
+	}
+
+	q.job.setJobStatus(JOBSTATUS_READY)
+	return resp, nil
+}
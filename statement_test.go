@@ -0,0 +1,77 @@
+package mapepire
+
+import "testing"
+
+// Prepare and execute a statement repeatedly
+func TestPrepare(t *testing.T) {
+	job, _ := initSQLTable("SELECT * FROM TEMPTEST", QueryOptions{})
+
+	stmt, err := job.Prepare("SELECT * FROM TEMPTEST WHERE ID = ?")
+	if err != nil {
+		t.Fatalf("should not throw error")
+	}
+	if stmt.ParameterCount != 1 {
+		t.Errorf("have %v, want 1", stmt.ParameterCount)
+	}
+
+	for _, id := range []int{1, 2, 3} {
+		resp, err := stmt.Execute(id)
+		if err != nil {
+			t.Errorf("should not throw error")
+			continue
+		}
+		if len(resp.Data) != 1 {
+			t.Errorf("have %v rows, want 1", len(resp.Data))
+		}
+	}
+
+	cached, _ := job.Prepare("SELECT * FROM TEMPTEST WHERE ID = ?")
+	if cached != stmt {
+		t.Errorf("should receive the cached statement")
+	}
+
+	err = stmt.Close()
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	_, err = stmt.Execute(1)
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// Execute a statement with the wrong number of parameters
+func TestStatementInvalidParameters(t *testing.T) {
+	stmt := &Statement{ID: "1", ParameterCount: 2, query: &Query{job: NewSQLJob("test")}}
+
+	_, err := stmt.Execute(1)
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+func TestPrepareInvalid(t *testing.T) {
+	job := NewSQLJob("test")
+	_, err := job.Prepare("")
+	if err == nil {
+		t.Errorf("should throw error")
+	}
+}
+
+// Statements of a replaced connection are closed
+func TestDropStatementCache(t *testing.T) {
+	job := NewSQLJob("test")
+	stmt := &Statement{ID: "1", SQL: "VALUES 1", query: &Query{job: job}}
+	job.statements.list = map[string]*Statement{stmt.SQL: stmt}
+
+	job.dropStatementCache()
+	if !stmt.closed.Load() {
+		t.Errorf("statement should be closed")
+	}
+	if _, err := stmt.Execute(); err == nil {
+		t.Errorf("should throw error")
+	}
+	if err := stmt.Close(); err != nil {
+		t.Errorf("should not throw error")
+	}
+}
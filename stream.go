@@ -0,0 +1,104 @@
+package mapepire
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of temporal values returned by the server
+var (
+	dateLayouts      = []string{"2006-01-02", "01/02/06", "02.01.06", "02.01.2006", "06/002", "06-01-02"}
+	timeLayouts      = []string{"15:04:05", "15.04.05"}
+	timestampLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02-15.04.05.999999999", "2006-01-02T15:04:05.999999999"}
+)
+
+// Executes the query and passes each page of results to fn, fetching more
+// rows until all rows have been received. Every page carries the metadata of
+// the first page, so its rows can be read with Rows.
+// If fn returns an error, the cursor is closed and the error is returned.
+func (q *Query) Stream(fn func(page *ServerResponse) error) error {
+	resp, err := q.Execute()
+	if err != nil {
+		return err
+	}
+	meta := resp.Metadata
+
+	for {
+		if resp.Metadata == nil {
+			resp.Metadata = meta
+		}
+		err = fn(resp)
+		if err != nil {
+			if q.state.Load() == STATE_RUN_MORE_DATA {
+				q.sqlCloseUnsafe(q.ID)
+			}
+			return err
+		}
+		if resp.IsDone || q.state.Load() != STATE_RUN_MORE_DATA {
+			return nil
+		}
+
+		resp, err = q.FetchNext()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Receive the rows of the response as values in the order of the columns,
+// whether the result is in terse format or not
+func (r *ServerResponse) Rows() [][]any {
+	if r.TerseData != nil {
+		return r.TerseData
+	}
+	if r.Metadata == nil {
+		return nil
+	}
+
+	rows := make([][]any, 0, len(r.Data))
+	for _, data := range r.Data {
+		row := make([]any, len(r.Metadata.Columns))
+		for i, column := range r.Metadata.Columns {
+			row[i] = data[column.Name]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// formats a value as text, numbers are never in exponent notation
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parses the value of a DATE, TIME or TIMESTAMP column
+func parseTemporal(columnType string, value string) (time.Time, bool) {
+	var layouts []string
+	switch strings.ToUpper(columnType) {
+	case "DATE":
+		layouts = dateLayouts
+	case "TIME":
+		layouts = timeLayouts
+	case "TIMESTAMP":
+		layouts = timestampLayouts
+	default:
+		return time.Time{}, false
+	}
+
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, strings.TrimSpace(value))
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
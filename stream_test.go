@@ -0,0 +1,75 @@
+package mapepire
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRows(t *testing.T) {
+	resp := &ServerResponse{
+		Metadata: &metadata{Columns: []column{{Name: "ID"}, {Name: "NAME"}}},
+		Data:     []map[string]interface{}{{"ID": 1.0, "NAME": "Max"}, {"NAME": "Olly", "ID": 2.0}},
+	}
+	have := resp.Rows()
+	if len(have) != 2 || have[0][0] != 1.0 || have[1][1] != "Olly" {
+		t.Errorf("have %v, want [[1 Max] [2 Olly]]", have)
+	}
+
+	terse := &ServerResponse{TerseData: [][]any{{1.0, "Max"}}}
+	if len(terse.Rows()) != 1 {
+		t.Errorf("should receive terse data")
+	}
+}
+
+func TestParseTemporal(t *testing.T) {
+	tests := []struct {
+		columnType string
+		value      string
+		want       time.Time
+	}{
+		{"DATE", "2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"TIME", "13.45.10", time.Date(0, 1, 1, 13, 45, 10, 0, time.UTC)},
+		{"TIMESTAMP", "2024-01-31-13.45.10.123456", time.Date(2024, 1, 31, 13, 45, 10, 123456000, time.UTC)},
+		{"TIMESTAMP", "2024-01-31 13:45:10.5", time.Date(2024, 1, 31, 13, 45, 10, 500000000, time.UTC)},
+	}
+	for _, test := range tests {
+		have, ok := parseTemporal(test.columnType, test.value)
+		if !ok || !have.Equal(test.want) {
+			t.Errorf("have %v, want %v", have, test.want)
+		}
+	}
+
+	if _, ok := parseTemporal("VARCHAR", "2024-01-31"); ok {
+		t.Errorf("should not parse VARCHAR")
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	if have := formatValue(1234567.0); have != "1234567" {
+		t.Errorf("have %v, want 1234567", have)
+	}
+	if have := formatValue(0.25); have != "0.25" {
+		t.Errorf("have %v, want 0.25", have)
+	}
+}
+
+// Stream all rows of a query
+func TestStream(t *testing.T) {
+	_, query := initSQLTable("SELECT * FROM TEMPTEST", QueryOptions{Rows: 2})
+
+	pages, rows := 0, 0
+	err := query.Stream(func(page *ServerResponse) error {
+		pages++
+		rows += len(page.Rows())
+		return nil
+	})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+	if rows != 5 {
+		t.Errorf("have %v rows, want 5", rows)
+	}
+	if pages != 3 {
+		t.Errorf("have %v pages, want 3", pages)
+	}
+}
@@ -1,5 +1,7 @@
 package mapepire
 
+import "time"
+
 // Represents the server response.
 type ServerResponse struct {
 	ID             string // The unique identifier of the request
@@ -15,6 +17,9 @@ type ServerResponse struct {
 	Error          error   // The error message, if any
 	SqlState       string  // The SQL state code
 	SqlRC          int     // The SQL error code
+
+	size    int           // Size of the message (in bytes)
+	latency time.Duration // Round-trip time of the request
 }
 
 // Represents trace configuration options
@@ -38,13 +43,15 @@ type traceData struct {
 type serverRequest struct {
 	id      string
 	jsonreq string
+	terse   bool // Whether the data is requested in terse format
 }
 
 // Represents metadata of the DB
 type metadata struct {
 	Job         string
 	Columns     []column
-	ColumnCount int `json:"column_count"`
+	ColumnCount int             `json:"column_count"`
+	Parameters  []ParameterInfo // Parameters of a prepared statement
 }
 
 // Represents the columns of the DB
@@ -54,3 +61,12 @@ type column struct {
 	Label       string
 	DisplaySize int `json:"display_size"`
 }
+
+// Represents a parameter of a prepared statement
+type ParameterInfo struct {
+	Name      string
+	Type      string
+	Mode      string // IN, OUT or INOUT
+	Precision int
+	Scale     int
+}
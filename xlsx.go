@@ -0,0 +1,369 @@
+package mapepire
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const XLSX_MAX_ROWS = 1048576
+
+// Cell styles of the XLSX export, as defined in styles.xml
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleDate
+	xlsxStyleTimestamp
+	xlsxStyleTime
+	xlsxStyleHeader
+)
+
+// Start of the serial dates of spreadsheets
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Represents options for the XLSX export
+type XLSXOptions struct {
+	MaxRows int // Max rows per sheet including the header, further rows continue on a new sheet
+}
+
+// Writes query results to an Excel workbook (XLSX), one or more sheets per query.
+// The rows are fetched page by page and written directly into the workbook.
+type XLSXWriter struct {
+	zip     *zip.Writer // The workbook archive
+	options XLSXOptions // Options of the export
+	sheets  []string    // Names of all sheets
+	closed  bool        // Whether the workbook has been closed
+}
+
+// Receive a new XLSX writer, the workbook is complete once it is closed
+func NewXLSXWriter(w io.Writer, options XLSXOptions) *XLSXWriter {
+	if options.MaxRows <= 1 || options.MaxRows > XLSX_MAX_ROWS {
+		options.MaxRows = XLSX_MAX_ROWS
+	}
+	return &XLSXWriter{zip: zip.NewWriter(w), options: options}
+}
+
+// Executes the query and writes all rows to a new sheet.
+// If the rows exceed the sheet size, they continue on further sheets.
+func (x *XLSXWriter) AddSheet(name string, query *Query) error {
+	if x.closed {
+		return fmt.Errorf("workbook has been closed")
+	}
+
+	var sheet *bufio.Writer
+	var columns []column
+	part, rows := 1, 0
+
+	err := query.Stream(func(page *ServerResponse) error {
+		if page.Metadata == nil {
+			return nil
+		}
+		columns = page.Metadata.Columns
+
+		for _, row := range page.Rows() {
+			if sheet == nil || rows >= x.options.MaxRows {
+				if sheet != nil {
+					err := endXLSXSheet(sheet)
+					if err != nil {
+						return err
+					}
+					part++
+				}
+
+				var err error
+				sheet, err = x.startSheet(name, part)
+				if err != nil {
+					return err
+				}
+				writeXLSXHeader(sheet, columns)
+				rows = 1
+			}
+
+			rows++
+			writeXLSXRow(sheet, rows, columns, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if sheet == nil {
+		sheet, err = x.startSheet(name, part)
+		if err != nil {
+			return err
+		}
+		writeXLSXHeader(sheet, columns)
+	}
+	return endXLSXSheet(sheet)
+}
+
+// Writes the remaining parts of the workbook
+func (x *XLSXWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	if len(x.sheets) == 0 {
+		sheet, err := x.startSheet("Sheet", 1)
+		if err != nil {
+			return err
+		}
+		err = endXLSXSheet(sheet)
+		if err != nil {
+			return err
+		}
+	}
+	x.closed = true
+
+	var types, workbook, rels strings.Builder
+	types.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i, name := range x.sheets {
+		id := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), id, id)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id)
+	}
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`, len(x.sheets)+1)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, file := range files {
+		f, err := x.zip.Create(file.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, file.content)
+		if err != nil {
+			return err
+		}
+	}
+	return x.zip.Close()
+}
+
+// Runs the SQL and writes all rows to a workbook with a single sheet
+func (s *SQLJob) ExportXLSX(w io.Writer, sql string, options XLSXOptions) error {
+	query, err := s.QueryWithOptions(sql, QueryOptions{Rows: MAX_FETCH_SIZE})
+	if err != nil {
+		return err
+	}
+
+	workbook := NewXLSXWriter(w, options)
+	err = workbook.AddSheet("Sheet", query)
+	if err != nil {
+		return err
+	}
+	return workbook.Close()
+}
+
+// starts a new sheet and writes the start of its XML
+func (x *XLSXWriter) startSheet(name string, part int) (*bufio.Writer, error) {
+	name = x.sheetName(name, part)
+	x.sheets = append(x.sheets, name)
+
+	f, err := x.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(f)
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return sheet, nil
+}
+
+// Receive a valid and unique sheet name
+func (x *XLSXWriter) sheetName(name string, part int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet"
+	}
+
+	for i := part; ; i++ {
+		candidate := truncate(name, 31)
+		if i > 1 {
+			suffix := fmt.Sprintf(" (%d)", i)
+			candidate = truncate(name, 31-len(suffix)) + suffix
+		}
+
+		unique := true
+		for _, sheet := range x.sheets {
+			if strings.EqualFold(sheet, candidate) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return candidate
+		}
+	}
+}
+
+// writes the end of a sheet
+func endXLSXSheet(sheet *bufio.Writer) error {
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.Flush()
+}
+
+// writes the header row with the labels of the columns
+func writeXLSXHeader(sheet *bufio.Writer, columns []column) {
+	sheet.WriteString(`<row r="1">`)
+	for i, c := range columns {
+		label := strings.TrimSpace(c.Label)
+		if label == "" {
+			label = c.Name
+		}
+		fmt.Fprintf(sheet, `<c r="%s1" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, xlsxColumn(i), xlsxStyleHeader, xmlEscape(label))
+	}
+	sheet.WriteString(`</row>`)
+}
+
+// writes a row with typed cells
+func writeXLSXRow(sheet *bufio.Writer, number int, columns []column, row []any) {
+	fmt.Fprintf(sheet, `<row r="%d">`, number)
+	for i, value := range row {
+		if value == nil {
+			continue
+		}
+		ref := xlsxColumn(i) + strconv.Itoa(number)
+		columnType := ""
+		if i < len(columns) {
+			columnType = strings.ToUpper(columns[i].Type)
+		}
+
+		switch v := value.(type) {
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(sheet, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+			continue
+		case float64:
+			fmt.Fprintf(sheet, `<c r="%s"><v>%s</v></c>`, ref, formatValue(v))
+			continue
+		}
+
+		text := formatValue(value)
+		if isNumericType(columnType) {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+				fmt.Fprintf(sheet, `<c r="%s"><v>%s</v></c>`, ref, strings.TrimSpace(text))
+				continue
+			}
+		}
+		if t, ok := parseTemporal(columnType, text); ok {
+			if serial, style, ok := xlsxSerial(columnType, t); ok {
+				fmt.Fprintf(sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(serial, 'f', -1, 64))
+				continue
+			}
+		}
+		fmt.Fprintf(sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(text))
+	}
+	sheet.WriteString(`</row>`)
+}
+
+// Receive the serial number and style of a temporal value.
+// Dates before 1900 have no serial number and are reported as not ok.
+func xlsxSerial(columnType string, t time.Time) (float64, int, bool) {
+	clock := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	fraction := (float64(clock) + float64(t.Nanosecond())/1e9) / 86400
+	if columnType == "TIME" {
+		return fraction, xlsxStyleTime, true
+	}
+	if t.Year() < 1900 {
+		return 0, 0, false
+	}
+
+	// calendar days instead of a time.Duration, which only covers about 292 years
+	days := float64(unixDays(t) - unixDays(xlsxEpoch))
+	if columnType == "DATE" {
+		return days, xlsxStyleDate, true
+	}
+	return days + fraction, xlsxStyleTimestamp, true
+}
+
+// receives the number of days between the Unix epoch and the date of t
+func unixDays(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// reports whether a column type is numeric
+func isNumericType(columnType string) bool {
+	switch columnType {
+	case "SMALLINT", "INTEGER", "BIGINT", "DECIMAL", "NUMERIC", "REAL", "DOUBLE", "FLOAT", "DECFLOAT":
+		return true
+	}
+	return false
+}
+
+// Receive the letters of a column, starting with A for 0
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// escapes text for XML
+func xmlEscape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// truncates a string to at most n runes
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return text
+}
+
+// Styles of the XLSX export: default, date, timestamp, time and bold header
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+	`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="21" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
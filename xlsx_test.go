@@ -0,0 +1,126 @@
+package mapepire
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if have := xlsxColumn(index); have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestXLSXSerial(t *testing.T) {
+	date := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	if have, style, _ := xlsxSerial("DATE", date); have != 45322 || style != xlsxStyleDate {
+		t.Errorf("have %v, want 45322", have)
+	}
+
+	noon := time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)
+	if have, style, _ := xlsxSerial("TIME", noon); have != 0.5 || style != xlsxStyleTime {
+		t.Errorf("have %v, want 0.5", have)
+	}
+
+	// the usual "no end date" of IBM i
+	end := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if have, _, _ := xlsxSerial("DATE", end); have != 2958465 {
+		t.Errorf("have %v, want 2958465", have)
+	}
+	if have, _, _ := xlsxSerial("TIMESTAMP", end.Add(18*time.Hour)); have != 2958465.75 {
+		t.Errorf("have %v, want 2958465.75", have)
+	}
+
+	if _, _, ok := xlsxSerial("DATE", time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("should have no serial before 1900")
+	}
+}
+
+func TestXLSXSheetName(t *testing.T) {
+	x := NewXLSXWriter(io.Discard, XLSXOptions{})
+	x.sheets = []string{"Employees"}
+
+	if have := x.sheetName("Employees", 1); have != "Employees (2)" {
+		t.Errorf("have %v, want 'Employees (2)'", have)
+	}
+	if have := x.sheetName("a/b:c", 1); have != "a_b_c" {
+		t.Errorf("have %v, want a_b_c", have)
+	}
+	if have := x.sheetName(strings.Repeat("x", 40), 1); len(have) != 31 {
+		t.Errorf("have %v characters, want 31", len(have))
+	}
+}
+
+func TestWriteXLSXRow(t *testing.T) {
+	var buf bytes.Buffer
+	sheet := bufio.NewWriter(&buf)
+	columns := []column{{Name: "ID", Type: "DECIMAL"}, {Name: "NAME", Type: "VARCHAR"}, {Name: "HIRED", Type: "DATE"}, {Name: "NOTE", Type: "VARCHAR"}}
+
+	writeXLSXRow(sheet, 2, columns, []any{1.0, "Max & Olly", "2024-01-31", nil})
+	sheet.Flush()
+
+	want := `<row r="2"><c r="A2"><v>1</v></c>` +
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">Max &amp; Olly</t></is></c>` +
+		`<c r="C2" s="1"><v>45322</v></c></row>`
+	if buf.String() != want {
+		t.Errorf("have %v, want %v", buf.String(), want)
+	}
+}
+
+// Close an empty workbook
+func TestXLSXClose(t *testing.T) {
+	var buf bytes.Buffer
+	x := NewXLSXWriter(&buf, XLSXOptions{})
+	err := x.Close()
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("should be a valid archive")
+	}
+	want := map[string]bool{
+		"[Content_Types].xml": true, "_rels/.rels": true, "xl/workbook.xml": true,
+		"xl/_rels/workbook.xml.rels": true, "xl/styles.xml": true, "xl/worksheets/sheet1.xml": true,
+	}
+	for _, f := range reader.File {
+		delete(want, f.Name)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing files %v", want)
+	}
+}
+
+// Export a query split over multiple sheets
+func TestExportXLSX(t *testing.T) {
+	job, _ := initSQLTable("SELECT * FROM TEMPTEST", QueryOptions{})
+
+	var buf bytes.Buffer
+	err := job.ExportXLSX(&buf, "SELECT * FROM TEMPTEST", XLSXOptions{MaxRows: 3})
+	if err != nil {
+		t.Errorf("should not throw error")
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("should be a valid archive")
+	}
+	sheets := 0
+	for _, f := range reader.File {
+		if strings.HasPrefix(f.Name, "xl/worksheets/") {
+			sheets++
+		}
+	}
+	if sheets != 3 {
+		t.Errorf("have %v sheets, want 3", sheets)
+	}
+}